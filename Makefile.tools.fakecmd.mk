@@ -5,6 +5,9 @@ tools/fakecmd/dist/prd/fakecmd: $(GO_SOURCES)
 tools/fakecmd/dist/e2e/fakecmd: $(GO_SOURCES)
 	mkdir -p $(dir $@) && go build -cover -o $@ tools/fakecmd/cmd/$(notdir $@)/*.go
 
+tools/fakecmd/behaviors.schema.json: $(GO_SOURCES)
+	go run ./tools/fakecmd/cmd/fakecmd admin schema > $@
+
 .PHONY: e2e-fakecmd-fakecmd
 e2e-fakecmd-fakecmd:
 	make start-e2e-environment
@@ -0,0 +1,178 @@
+package fakecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/libs/e2ehelpers"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestFakeCMDAdmin(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(fmt.Sprintf("/tmp/%s", uuid.NewString()))
+	filePathBehaviors := e2ehelpers.MustWriteFileAtRandomPath("/tmp", e2ehelpers.MustMarshalJSON(domains.Behaviors{
+		{
+			Type: domains.BehaviorTypeStdoutStderrExitCode,
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout: "this is a test stdout",
+			},
+		},
+	}))
+	filePathInvalidBehaviors := e2ehelpers.MustWriteFileAtRandomPath("/tmp", []byte(`{"Version":1,"Behaviors":[{"Type":1}]}`))
+	t.Cleanup(func() {
+		os.RemoveAll(dirPath.String())         //nolint:errcheck
+		os.RemoveAll(filePathBehaviors)        //nolint:errcheck
+		os.RemoveAll(filePathInvalidBehaviors) //nolint:errcheck
+	})
+
+	runFake := func(t *testing.T) {
+		out, err := exec.Command(dirPath.FilePathCommand(), "plan").Output()
+		require.NoError(t, err)
+		assert.Equal(t, "this is a test stdout", string(out))
+	}
+
+	testCases := []e2ehelpers.CLITestCaseV2{
+		{
+			Desc: "ng - no subcommand",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin"}
+				expected.ExitCode = 1
+				expected.Stderr = usageStringAdmin
+			},
+		},
+		{
+			Desc: "ng - unknown subcommand",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "foo"}
+				expected.ExitCode = 1
+				expected.Stderr = "unknown subcommand: foo\n" + usageStringAdmin
+			},
+		},
+		{
+			Desc: "ng - -dir is required",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "history"}
+				expected.ExitCode = 1
+				expected.Stderr = "-dir is required"
+			},
+		},
+		{
+			Desc: "ng - not initialized",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "verify", "-dir", dirPath.String()}
+				expected.ExitCode = 1
+				expected.Stderr = fmt.Sprintf(
+					"fake command is not initialized: %s: stat %s: no such file or directory",
+					dirPath, dirPath.FilePathBehaviors(),
+				)
+			},
+		},
+		{
+			Desc: "ng - init with invalid behaviors",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{
+					"admin", "init",
+					"-dir", dirPath.String(),
+					"-behaviors", filePathInvalidBehaviors,
+				}
+				expected.ExitCode = 1
+				expected.Stderr = e2ehelpers.NewLines(
+					fmt.Sprintf("invalid behaviors: %s:", filePathInvalidBehaviors),
+					"Behaviors[0].BehaviorStdoutStderrExitCode: required when Type is stdoutStderrExitCode",
+				)
+			},
+			Assertions: func(t *testing.T) {
+				assert.NoDirExists(t, dirPath.String())
+			},
+		},
+		{
+			Desc: "ok - init",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{
+					"admin", "init",
+					"-dir", dirPath.String(),
+					"-behaviors", filePathBehaviors,
+					"-env-allowlist", "FAKECMD_E2E_FOO,FAKECMD_E2E_BAR",
+				}
+			},
+			Assertions: func(t *testing.T) {
+				require.FileExists(t, dirPath.FilePathCommand())
+				config, err := domains.ReadConfig(dirPath.FilePathConfig())
+				require.NoError(t, err)
+				assert.Equal(t, []string{"FAKECMD_E2E_FOO", "FAKECMD_E2E_BAR"}, config.EnvAllowlist)
+			},
+		},
+		{
+			Desc: "ng - verify before the fake command is called",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "verify", "-dir", dirPath.String()}
+				expected.ExitCode = 1
+				expected.Stderr = e2ehelpers.NewLines(
+					"some behaviors are not consumed:",
+					"behaviors[0]: any",
+				)
+			},
+		},
+		{
+			Desc: "ok - verify after the fake command is called",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				runFake(t)
+				input.Args = []string{"admin", "verify", "-dir", dirPath.String()}
+				expected.Stdout = "all behaviors are consumed"
+			},
+		},
+		{
+			Desc: "ok - history",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "history", "-dir", dirPath.String()}
+				histories, err := domains.ReadState(dirPath.FilePathState())
+				require.NoError(t, err)
+				b, err := json.MarshalIndent(histories.ExecutedHistories, "", "  ")
+				require.NoError(t, err)
+				expected.Stdout = string(b)
+			},
+		},
+		{
+			Desc: "ok - reset",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "reset", "-dir", dirPath.String()}
+			},
+			Assertions: func(t *testing.T) {
+				assert.NoFileExists(t, dirPath.FilePathState())
+				runFake(t)
+			},
+		},
+		{
+			Desc: "ok - schema",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				input.Args = []string{"admin", "schema"}
+				b, err := domains.SpecJSONSchema()
+				require.NoError(t, err)
+				expected.Stdout = string(b)
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.Desc, func(t *testing.T) {
+			tC.Run(t, filePathBin)
+		})
+	}
+}
+
+var usageStringAdmin = `Usage: fakecmd admin <subcommand> [flags]
+
+Subcommands:
+  init     initialize a fake command in a directory
+  history  print recorded invocations of a fake command as JSON
+  reset    remove recorded invocations of a fake command
+  verify   exit not zero if some behaviors of a fake command have not been used
+  schema   print the JSON Schema of the behaviors file
+`
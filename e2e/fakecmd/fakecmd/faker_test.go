@@ -0,0 +1,43 @@
+package fakecmd
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestFakerInstall(t *testing.T) {
+	commandFaker := domains.MustByEnv()
+	defer commandFaker.Cleanup() //nolint:errcheck
+
+	terraform := commandFaker.AddNamedInTest(t, "terraform", domains.Behaviors{
+		{
+			Type: domains.BehaviorTypeStdoutStderrExitCode,
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout: "this is terraform\n",
+			},
+		},
+	})
+	git := commandFaker.AddNamedInTest(t, "git", domains.Behaviors{
+		{
+			Type: domains.BehaviorTypeStdoutStderrExitCode,
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout: "this is git\n",
+			},
+		},
+	})
+
+	// commands are looked up via PATH by the shell
+	cmd := exec.Command("/bin/sh", "-c", "terraform version && git status")
+	cmd.Env = append(os.Environ(), commandFaker.PathEnvInTest(t))
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "this is terraform\nthis is git\n", string(out))
+
+	terraform.AssertCalledWith(t, 0, "version")
+	git.AssertCalledWith(t, 0, "status")
+}
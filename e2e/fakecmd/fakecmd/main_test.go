@@ -1,12 +1,20 @@
 package fakecmd
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/suzuito/sandbox2-common-go/libs/e2ehelpers"
 	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
@@ -17,6 +25,9 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 
 	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
 
+	var heldLock *os.File
+	filePathScriptOutput := fmt.Sprintf("/tmp/%s", uuid.NewString())
+
 	testCases := []e2ehelpers.CLITestCaseV2{
 		{
 			Desc: "ng - command's behaviors file does not exist",
@@ -44,6 +55,37 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 				)
 			},
 		},
+		{
+			Desc: "ng - command's behaviors file has an unknown field",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteFile(
+					dirPath.FilePathBehaviors(),
+					[]byte(`{"Version":1,"Behavior":[]}`),
+				)
+
+				expected.ExitCode = 127
+				expected.Stderr = fmt.Sprintf(
+					`FAKE_CMD_ERROR failed to unmarshal behavior file: %s: json: unknown field "Behavior"`,
+					dirPath.FilePathBehaviors(),
+				)
+			},
+		},
+		{
+			Desc: "ng - command's behaviors file is invalid",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteFile(
+					dirPath.FilePathBehaviors(),
+					[]byte(`{"Version":2,"Behaviors":[{"Type":6}]}`),
+				)
+
+				expected.ExitCode = 127
+				expected.Stderr = e2ehelpers.NewLines(
+					fmt.Sprintf("FAKE_CMD_ERROR invalid behavior file: %s:", dirPath.FilePathBehaviors()),
+					"Version: unsupported version 2 (supported: 1)",
+					"Behaviors[0].Type: unknown value 6",
+				)
+			},
+		},
 		{
 			Desc: "ng - fake no command's behaviors",
 			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
@@ -83,7 +125,7 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 					errors.Join(
 						os.RemoveAll(dirPath.FilePathBehaviors()),
 						os.RemoveAll(dirPath.FilePathState()),
-						os.RemoveAll(dirPath.FilePathProcessing()),
+						os.RemoveAll(dirPath.FilePathLock()),
 					),
 				)
 			},
@@ -118,7 +160,7 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 				require.NoError(t, errors.Join(
 					os.RemoveAll(dirPath.FilePathBehaviors()),
 					os.RemoveAll(dirPath.FilePathState()),
-					os.RemoveAll(dirPath.FilePathProcessing()),
+					os.RemoveAll(dirPath.FilePathLock()),
 				))
 			},
 		},
@@ -149,7 +191,7 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 					dirPath.FilePathState(),
 					&domains.State{
 						ExecutedHistories: domains.ExecutedHistories{
-							{},
+							{BehaviorIndex: 0},
 						},
 					},
 				)
@@ -161,7 +203,7 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 				require.NoError(t, errors.Join(
 					os.RemoveAll(dirPath.FilePathBehaviors()),
 					os.RemoveAll(dirPath.FilePathState()),
-					os.RemoveAll(dirPath.FilePathProcessing()),
+					os.RemoveAll(dirPath.FilePathLock()),
 				))
 			},
 		},
@@ -192,7 +234,7 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 					dirPath.FilePathState(),
 					&domains.State{
 						ExecutedHistories: domains.ExecutedHistories{
-							{}, {},
+							{BehaviorIndex: 0}, {BehaviorIndex: 1},
 						},
 					},
 				)
@@ -204,7 +246,353 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 				require.NoError(t, errors.Join(
 					os.RemoveAll(dirPath.FilePathBehaviors()),
 					os.RemoveAll(dirPath.FilePathState()),
-					os.RemoveAll(dirPath.FilePathProcessing()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - fake a command's behavior matched by args",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							ArgsMatcher: &domains.ArgsMatcher{
+								Type: domains.ArgsMatcherTypeExact,
+								Args: []string{"init"},
+							},
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								Stdout:   "this is a test stdout1",
+								ExitCode: 11,
+							},
+						},
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							ArgsMatcher: &domains.ArgsMatcher{
+								Type: domains.ArgsMatcherTypePrefix,
+								Args: []string{"plan"},
+							},
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								Stdout:   "this is a test stdout2",
+								ExitCode: 12,
+							},
+						},
+					},
+				)
+
+				input.Args = []string{"plan", "-no-color"}
+				expected.ExitCode = 12
+				expected.Stdout = "this is a test stdout2"
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ng - no behaviors matched by args",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							ArgsMatcher: &domains.ArgsMatcher{
+								Type: domains.ArgsMatcherTypeExact,
+								Args: []string{"init"},
+							},
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								ExitCode: 11,
+							},
+						},
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							ArgsMatcher: &domains.ArgsMatcher{
+								Type: domains.ArgsMatcherTypeRegexp,
+								Args: []string{"^-chdir=.+$", "plan"},
+							},
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								ExitCode: 12,
+							},
+						},
+					},
+				)
+
+				input.Args = []string{"apply", "-no-color"}
+				expected.ExitCode = 127
+				expected.Stderr = e2ehelpers.NewLines(
+					`FAKE_CMD_ERROR no behaviors matched: args=["apply" "-no-color"]`,
+					`FAKE_CMD_ERROR   behaviors[0]: exact ["init"]`,
+					`FAKE_CMD_ERROR   behaviors[1]: regexp ["^-chdir=.+$" "plan"]`,
+				)
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - invocation is recorded in state",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								Stdout: "this is a test stdout",
+							},
+						},
+					},
+				)
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathConfig(),
+					domains.Config{EnvAllowlist: []string{"FAKECMD_E2E_FOO"}},
+				)
+
+				input.Args = []string{"-chdir=/x", "plan", "-no-color"}
+				input.Envs = []string{"FAKECMD_E2E_FOO=foo", "FAKECMD_E2E_BAR=bar"}
+				expected.Stdout = "this is a test stdout"
+			},
+			Assertions: func(t *testing.T) {
+				cwd, err := os.Getwd()
+				require.NoError(t, err)
+
+				state, err := domains.ReadState(dirPath.FilePathState())
+				require.NoError(t, err)
+				require.Len(t, state.ExecutedHistories, 1)
+
+				h := state.ExecutedHistories[0]
+				assert.Equal(t, 0, h.BehaviorIndex)
+				assert.Equal(t, []string{"-chdir=/x", "plan", "-no-color"}, h.Args)
+				assert.Equal(t, cwd, h.Cwd)
+				assert.Equal(t, map[string]string{"FAKECMD_E2E_FOO": "foo"}, h.Env)
+				assert.Empty(t, h.Stdin)
+				assert.False(t, h.StartedAt.IsZero())
+				assert.False(t, h.FinishedAt.Before(h.StartedAt))
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathConfig()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - fake a command's scripted behavior",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypeScript,
+							BehaviorScript: &domains.BehaviorScript{
+								Steps: []domains.ScriptStep{
+									{Type: domains.ScriptStepTypeStdout, Content: "this is a test stdout"},
+									{Type: domains.ScriptStepTypeWriteFile, Path: filePathScriptOutput, Content: "this is a test file"},
+									{Type: domains.ScriptStepTypeStderr, Content: "this is a test stderr"},
+									{Type: domains.ScriptStepTypeExit, ExitCode: 3},
+								},
+							},
+						},
+					},
+				)
+
+				expected.ExitCode = 3
+				expected.Stdout = "this is a test stdout"
+				expected.Stderr = "this is a test stderr"
+			},
+			Assertions: func(t *testing.T) {
+				b, err := os.ReadFile(filePathScriptOutput)
+				require.NoError(t, err)
+				assert.Equal(t, "this is a test file", string(b))
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+					os.RemoveAll(filePathScriptOutput),
+				))
+			},
+		},
+		{
+			Desc: "ok - pass through to the real command and record it",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypePassthrough,
+							BehaviorPassthrough: &domains.BehaviorPassthrough{
+								FilePathBin: "/bin/sh",
+							},
+						},
+					},
+				)
+
+				input.Args = []string{"-c", "echo this is a test stdout; exit 4"}
+				expected.ExitCode = 4
+				expected.Stdout = "this is a test stdout"
+			},
+			Assertions: func(t *testing.T) {
+				recordings, err := domains.ReadBehaviorsFile(dirPath.FilePathRecordings())
+				require.NoError(t, err)
+				assert.Equal(t, domains.Behaviors{
+					{
+						Type: domains.BehaviorTypeStdoutStderrExitCode,
+						ArgsMatcher: &domains.ArgsMatcher{
+							Type: domains.ArgsMatcherTypeExact,
+							Args: []string{"-c", "echo this is a test stdout; exit 4"},
+						},
+						BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+							Stdout:   "this is a test stdout\n",
+							ExitCode: 4,
+						},
+					},
+				}, recordings)
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathRecordings()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - fake a command's templated behavior",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								Stdout:   `plan of {{index .Args 0}} ({{.Index}})`,
+								Stderr:   `{{.Env.FAKECMD_E2E_FOO}}`,
+								Template: true,
+							},
+						},
+					},
+				)
+
+				input.Args = []string{"-chdir=/x", "plan"}
+				input.Envs = []string{"FAKECMD_E2E_FOO=foo"}
+				expected.Stdout = "plan of -chdir=/x (0)"
+				expected.Stderr = "foo"
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - fall back to the default behavior after all executions are done",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Spec{
+						Version: domains.SpecVersion,
+						Behaviors: domains.Behaviors{
+							{
+								Type: domains.BehaviorTypeStdoutStderrExitCode,
+								BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+									Stdout: "this is a test stdout1",
+								},
+							},
+						},
+						WhenExhausted: domains.WhenExhaustedDefault,
+						DefaultBehavior: &domains.Behavior{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								Stdout:   "this is a default stdout",
+								ExitCode: 9,
+							},
+						},
+					},
+				)
+
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathState(),
+					&domains.State{
+						ExecutedHistories: domains.ExecutedHistories{
+							{BehaviorIndex: 0},
+						},
+					},
+				)
+
+				expected.ExitCode = 9
+				expected.Stdout = "this is a default stdout"
+			},
+			Assertions: func(t *testing.T) {
+				state, err := domains.ReadState(dirPath.FilePathState())
+				require.NoError(t, err)
+				require.Len(t, state.ExecutedHistories, 2)
+				assert.Equal(t, domains.BehaviorIndexDefault, state.ExecutedHistories[1].BehaviorIndex)
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - repeat the last behavior after all executions are done",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Spec{
+						Version: domains.SpecVersion,
+						Behaviors: domains.Behaviors{
+							{
+								Type: domains.BehaviorTypeStdoutStderrExitCode,
+								BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+									Stdout: "this is a test stdout1",
+								},
+							},
+							{
+								Type: domains.BehaviorTypeStdoutStderrExitCode,
+								BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+									Stdout: "this is a test stdout2",
+								},
+							},
+						},
+						WhenExhausted: domains.WhenExhaustedRepeatLast,
+					},
+				)
+
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathState(),
+					&domains.State{
+						ExecutedHistories: domains.ExecutedHistories{
+							{BehaviorIndex: 0}, {BehaviorIndex: 1}, {BehaviorIndex: 1},
+						},
+					},
+				)
+
+				expected.Stdout = "this is a test stdout2"
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
 				))
 			},
 		},
@@ -224,19 +612,59 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 					},
 				)
 
-				e2ehelpers.MustWriteJSONFile(dirPath.FilePathProcessing(), struct{}{})
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathConfig(),
+					domains.Config{LockTimeout: 100 * time.Millisecond},
+				)
+
+				// another process holds the lock
+				var err error
+				heldLock, err = os.OpenFile(dirPath.FilePathLock(), os.O_CREATE|os.O_RDWR, 0644)
+				require.NoError(t, err)
+				require.NoError(t, syscall.Flock(int(heldLock.Fd()), syscall.LOCK_EX))
 
 				expected.ExitCode = 127
 				expected.Stderr = fmt.Sprintf(
-					"FAKE_CMD_ERROR failed to get lock: %s: processing file already exists",
-					dirPath.FilePathProcessing(),
+					"FAKE_CMD_ERROR failed to get lock: %s: timed out after 100ms",
+					dirPath.FilePathLock(),
 				)
 			},
 			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
 				require.NoError(t, errors.Join(
+					heldLock.Close(),
 					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathConfig()),
 					os.RemoveAll(dirPath.FilePathState()),
-					os.RemoveAll(dirPath.FilePathProcessing()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			},
+		},
+		{
+			Desc: "ok - stale lock file does not block",
+			Setup: func(t *testing.T, testID e2ehelpers.TestID, input *e2ehelpers.CLITestCaseV2Input, expected *e2ehelpers.CLITestCaseV2Expected) {
+				e2ehelpers.MustWriteJSONFile(
+					dirPath.FilePathBehaviors(),
+					domains.Behaviors{
+						{
+							Type: domains.BehaviorTypeStdoutStderrExitCode,
+							BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+								Stdout:   "this is a test stdout1",
+								ExitCode: 11,
+							},
+						},
+					},
+				)
+
+				e2ehelpers.MustWriteFile(dirPath.FilePathLock(), []byte{})
+
+				expected.ExitCode = 11
+				expected.Stdout = "this is a test stdout1"
+			},
+			Teardown: func(t *testing.T, testID e2ehelpers.TestID) {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
 				))
 			},
 		},
@@ -272,7 +700,7 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 				require.NoError(t, errors.Join(
 					os.RemoveAll(dirPath.FilePathBehaviors()),
 					os.RemoveAll(dirPath.FilePathState()),
-					os.RemoveAll(dirPath.FilePathProcessing()),
+					os.RemoveAll(dirPath.FilePathLock()),
 				))
 			},
 		},
@@ -290,3 +718,380 @@ func TestFakeCMDFakeCMD(t *testing.T) {
 		})
 	}
 }
+
+func TestFakeCMDFakeCMDConcurrentInvocations(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
+
+	n := 10
+	behaviors := domains.Behaviors{}
+	for i := range n {
+		behaviors = append(behaviors, domains.Behavior{
+			Type: domains.BehaviorTypeStdoutStderrExitCode,
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout: fmt.Sprintf("stdout%d", i),
+			},
+		})
+	}
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathBehaviors(), behaviors)
+	t.Cleanup(func() {
+		require.NoError(t, errors.Join(
+			os.RemoveAll(dirPath.FilePathBehaviors()),
+			os.RemoveAll(dirPath.FilePathState()),
+			os.RemoveAll(dirPath.FilePathLock()),
+		))
+	})
+
+	errs := make(chan error, n)
+	for range n {
+		go func() {
+			errs <- exec.Command(filePathBin).Run()
+		}()
+	}
+	for range n {
+		require.NoError(t, <-errs)
+	}
+
+	state, err := domains.ReadState(dirPath.FilePathState())
+	require.NoError(t, err)
+	assert.Len(t, state.UsedBehaviorIndexes(), n)
+}
+
+func TestFakeCMDFakeCMDSignals(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
+
+	waitUntilReady := func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			state, err := domains.ReadState(dirPath.FilePathState())
+			return err == nil && len(state.ExecutedHistories) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	testCases := []struct {
+		desc         string
+		behavior     domains.BehaviorSignal
+		cancel       bool
+		wantExitCode int
+		wantSignaled syscall.Signal
+		wantSignals  []string
+	}{
+		{
+			desc: "ok - wait for signal and exit",
+			behavior: domains.BehaviorSignal{
+				Mode:     domains.SignalModeWaitForSignal,
+				ExitCode: 143,
+			},
+			cancel:       true,
+			wantExitCode: 143,
+			wantSignals:  []string{"SIGTERM"},
+		},
+		{
+			desc: "ok - ignore SIGTERM",
+			behavior: domains.BehaviorSignal{
+				Mode:     domains.SignalModeIgnoreSIGTERM,
+				Duration: 500 * time.Millisecond,
+				ExitCode: 2,
+			},
+			cancel:       true,
+			wantExitCode: 2,
+			wantSignals:  []string{"SIGTERM"},
+		},
+		{
+			desc: "ok - kill self",
+			behavior: domains.BehaviorSignal{
+				Mode:   domains.SignalModeKillSelf,
+				Signal: "SIGKILL",
+			},
+			wantExitCode: -1,
+			wantSignaled: syscall.SIGKILL,
+		},
+		{
+			desc: "ok - kill self with SIGTERM",
+			behavior: domains.BehaviorSignal{
+				Mode:   domains.SignalModeKillSelf,
+				Signal: "SIGTERM",
+			},
+			wantExitCode: -1,
+			wantSignaled: syscall.SIGTERM,
+		},
+		{
+			desc: "ok - kill self with SIGINT",
+			behavior: domains.BehaviorSignal{
+				Mode:   domains.SignalModeKillSelf,
+				Signal: "SIGINT",
+			},
+			wantExitCode: -1,
+			wantSignaled: syscall.SIGINT,
+		},
+		{
+			desc: "ok - kill self with SIGHUP",
+			behavior: domains.BehaviorSignal{
+				Mode:   domains.SignalModeKillSelf,
+				Signal: "SIGHUP",
+			},
+			wantExitCode: -1,
+			wantSignaled: syscall.SIGHUP,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			e2ehelpers.MustWriteJSONFile(dirPath.FilePathBehaviors(), domains.Behaviors{
+				{
+					Type:           domains.BehaviorTypeSignal,
+					BehaviorSignal: &tC.behavior,
+				},
+			})
+			t.Cleanup(func() {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cmd := exec.CommandContext(ctx, filePathBin)
+			cmd.Cancel = func() error {
+				return cmd.Process.Signal(syscall.SIGTERM)
+			}
+			require.NoError(t, cmd.Start())
+
+			if tC.cancel {
+				waitUntilReady(t)
+				cancel()
+			}
+
+			err := cmd.Wait()
+			if err != nil {
+				var exiterr *exec.ExitError
+				require.ErrorAs(t, err, &exiterr)
+			}
+			assert.Equal(t, tC.wantExitCode, cmd.ProcessState.ExitCode())
+			if tC.wantSignaled != 0 {
+				status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+				require.True(t, ok)
+				assert.Equal(t, tC.wantSignaled, status.Signal())
+			}
+
+			state, err := domains.ReadState(dirPath.FilePathState())
+			require.NoError(t, err)
+			require.Len(t, state.ExecutedHistories, 1)
+			assert.Equal(t, tC.wantSignals, state.ExecutedHistories[0].ReceivedSignals)
+		})
+	}
+}
+
+func TestFakeCMDFakeCMDStream(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
+
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathBehaviors(), domains.Behaviors{
+		{
+			Type: domains.BehaviorTypeStream,
+			BehaviorStream: &domains.BehaviorStream{
+				Chunks: []domains.StreamChunk{
+					{Target: domains.StreamTargetStdout, Content: "out1\n"},
+					{Target: domains.StreamTargetStderr, Content: "err1\n", Delay: 10 * time.Millisecond},
+					{Target: domains.StreamTargetStdout, Content: "out2\n", Delay: 10 * time.Millisecond},
+					{Target: domains.StreamTargetStderr, Content: "err2\n", Delay: 10 * time.Millisecond},
+				},
+				ExitCode: 5,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		require.NoError(t, errors.Join(
+			os.RemoveAll(dirPath.FilePathBehaviors()),
+			os.RemoveAll(dirPath.FilePathState()),
+			os.RemoveAll(dirPath.FilePathLock()),
+		))
+	})
+
+	// stdout and stderr share a single pipe, so the order of chunks is observable
+	combined := bytes.NewBufferString("")
+	cmd := exec.Command(filePathBin)
+	cmd.Stdout = combined
+	cmd.Stderr = combined
+	err := cmd.Run()
+	var exiterr *exec.ExitError
+	require.ErrorAs(t, err, &exiterr)
+	assert.Equal(t, 5, cmd.ProcessState.ExitCode())
+	assert.Equal(t, "out1\nerr1\nout2\nerr2\n", combined.String())
+}
+
+func TestFakeCMDFakeCMDStdinMatcher(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
+
+	testCases := []struct {
+		desc         string
+		stdin        string
+		wantExitCode int
+		wantStdout   string
+		wantStderr   string
+	}{
+		{
+			desc:         "ok - stdin matches",
+			stdin:        `{"b": [1, 2], "a": "x"}`,
+			wantExitCode: 0,
+			wantStdout:   "applied",
+		},
+		{
+			desc:         "ng - stdin does not match",
+			stdin:        `{"a": "x", "b": [1, 3]}`,
+			wantExitCode: 125,
+			wantStderr: e2ehelpers.NewLines(
+				"FAKE_CMD_ERROR stdin does not match: behaviors[0]: json",
+				"FAKE_CMD_ERROR   --- Expected",
+				"FAKE_CMD_ERROR   +++ Actual",
+				"FAKE_CMD_ERROR   @@ -4,3 +4,3 @@",
+				"FAKE_CMD_ERROR        1,",
+				"FAKE_CMD_ERROR   -    2",
+				"FAKE_CMD_ERROR   +    3",
+				"FAKE_CMD_ERROR      ]",
+			),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			e2ehelpers.MustWriteJSONFile(dirPath.FilePathBehaviors(), domains.Behaviors{
+				{
+					Type: domains.BehaviorTypeStdoutStderrExitCode,
+					StdinMatcher: &domains.StdinMatcher{
+						Type:    domains.StdinMatcherTypeJSON,
+						Content: `{"a": "x", "b": [1, 2]}`,
+					},
+					BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+						Stdout: "applied",
+					},
+				},
+			})
+			t.Cleanup(func() {
+				require.NoError(t, errors.Join(
+					os.RemoveAll(dirPath.FilePathBehaviors()),
+					os.RemoveAll(dirPath.FilePathState()),
+					os.RemoveAll(dirPath.FilePathLock()),
+				))
+			})
+
+			stdout := bytes.NewBufferString("")
+			stderr := bytes.NewBufferString("")
+			cmd := exec.Command(filePathBin, "apply")
+			cmd.Stdin = strings.NewReader(tC.stdin)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			cmd.Run() //nolint:errcheck
+			assert.Equal(t, tC.wantExitCode, cmd.ProcessState.ExitCode())
+			assert.Equal(t, tC.wantStdout, stdout.String())
+			assert.Equal(t, tC.wantStderr, stderr.String())
+
+			state, err := domains.ReadState(dirPath.FilePathState())
+			require.NoError(t, err)
+			require.Len(t, state.ExecutedHistories, 1)
+			assert.Equal(t, tC.stdin, string(state.ExecutedHistories[0].Stdin))
+		})
+	}
+}
+
+func TestFakeCMDFakeCMDStdinNeverClosed(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathBehaviors(), domains.Behaviors{
+		{
+			Type: domains.BehaviorTypeStdoutStderrExitCode,
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout: "done",
+			},
+		},
+	})
+	t.Cleanup(func() {
+		require.NoError(t, errors.Join(
+			os.RemoveAll(dirPath.FilePathBehaviors()),
+			os.RemoveAll(dirPath.FilePathState()),
+			os.RemoveAll(dirPath.FilePathLock()),
+		))
+	})
+
+	// stdin is a pipe inherited from the caller which is never closed, like os.Stdin under a CI runner
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer w.Close() //nolint:errcheck
+	defer r.Close() //nolint:errcheck
+
+	stdout := bytes.NewBufferString("")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, filePathBin)
+	cmd.Stdin = r
+	cmd.Stdout = stdout
+	require.NoError(t, cmd.Run())
+	assert.Equal(t, "done", stdout.String())
+}
+
+func TestFakeCMDFakeCMDParallelSignals(t *testing.T) {
+	filePathBin := os.Getenv("FILE_PATH_BIN")
+
+	dirPath := domains.DirPathFakeCommand(filepath.Dir(filePathBin))
+
+	// Both invocations must wait for a signal at the same time.
+	// The lock must not be held while waiting, otherwise the second invocation times out.
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathBehaviors(), domains.Behaviors{
+		{
+			Type:           domains.BehaviorTypeSignal,
+			BehaviorSignal: &domains.BehaviorSignal{Mode: domains.SignalModeWaitForSignal, ExitCode: 143},
+		},
+		{
+			Type:           domains.BehaviorTypeSignal,
+			BehaviorSignal: &domains.BehaviorSignal{Mode: domains.SignalModeWaitForSignal, ExitCode: 130},
+		},
+	})
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathConfig(), domains.Config{LockTimeout: time.Second})
+	t.Cleanup(func() {
+		require.NoError(t, errors.Join(
+			os.RemoveAll(dirPath.FilePathBehaviors()),
+			os.RemoveAll(dirPath.FilePathConfig()),
+			os.RemoveAll(dirPath.FilePathState()),
+			os.RemoveAll(dirPath.FilePathLock()),
+		))
+	})
+
+	cmds := []*exec.Cmd{}
+	stderr := bytes.NewBufferString("")
+	for range 2 {
+		cmd := exec.Command(filePathBin)
+		cmd.Stderr = stderr
+		require.NoError(t, cmd.Start())
+		cmds = append(cmds, cmd)
+	}
+
+	require.Eventually(t, func() bool {
+		state, err := domains.ReadState(dirPath.FilePathState())
+		return err == nil && len(state.ExecutedHistories) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	// wait longer than LockTimeout to make sure that the lock is not held while waiting
+	time.Sleep(1500 * time.Millisecond)
+
+	exitCodes := []int{}
+	for _, cmd := range cmds {
+		require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+		cmd.Wait() //nolint:errcheck
+		exitCodes = append(exitCodes, cmd.ProcessState.ExitCode())
+	}
+	assert.ElementsMatch(t, []int{143, 130}, exitCodes)
+	assert.Empty(t, stderr.String())
+
+	state, err := domains.ReadState(dirPath.FilePathState())
+	require.NoError(t, err)
+	require.Len(t, state.ExecutedHistories, 2)
+	for _, h := range state.ExecutedHistories {
+		assert.Equal(t, []string{"SIGTERM"}, h.ReceivedSignals)
+		assert.False(t, h.FinishedAt.IsZero())
+	}
+}
@@ -2,14 +2,23 @@ package hfs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/libs/e2ehelpers"
 	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver"
 )
 
@@ -210,3 +219,766 @@ func TestEdgeCases(t *testing.T) {
 		)
 	})
 }
+
+func TestBodyMatcher(t *testing.T) {
+	cli := http.DefaultClient
+
+	// setup: モックをクリアする
+	req, err := http.NewRequest(http.MethodDelete, targetURL+"/admin/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	// setup: 同じエンドポイントに対してbodyだけ異なる2つのモックを登録する
+	mocks := []httpfakeserver.Mock{
+		{
+			Request: httpfakeserver.Request{
+				Method: "POST",
+				Path:   "/repos/o/r/issues/1/comments",
+				Body: &httpfakeserver.BodyMatcher{
+					Type: httpfakeserver.BodyMatcherTypeJSONPath,
+					JSONPath: []httpfakeserver.JSONPathPredicate{
+						{Path: "$.body", Matches: "^plan"},
+					},
+				},
+			},
+			Response: httpfakeserver.Response{Status: http.StatusCreated, Body: `{"id":1}`},
+		},
+		{
+			Request: httpfakeserver.Request{
+				Method: "POST",
+				Path:   "/repos/o/r/issues/1/comments",
+				Body: &httpfakeserver.BodyMatcher{
+					Type:  httpfakeserver.BodyMatcherTypeJSON,
+					Value: `{"body":"apply succeeded"}`,
+				},
+			},
+			Response: httpfakeserver.Response{Status: http.StatusCreated, Body: `{"id":2}`},
+		},
+	}
+	for _, m := range mocks {
+		res, err = cli.Post(
+			targetURL+"/admin/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, m)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	}
+
+	testCases := []struct {
+		desc       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "matched by jsonpath",
+			body:       `{"body":"plan: 1 to add"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":1}`,
+		},
+		{
+			desc:       "matched by json",
+			body:       `{ "body" : "apply succeeded" }`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":2}`,
+		},
+		{
+			desc:       "not matched",
+			body:       `{"body":"foo"}`,
+			wantStatus: http.StatusNotImplemented,
+			wantBody:   "no matched to cases",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			res, err := cli.Post(
+				targetURL+"/repos/o/r/issues/1/comments", "application/json",
+				bytes.NewBufferString(tC.body),
+			)
+			require.NoError(t, err)
+			defer res.Body.Close() //nolint:errcheck
+
+			assert.Equal(t, tC.wantStatus, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tC.wantBody, string(body))
+		})
+	}
+
+	t.Run("invalid body matcher returns 400", func(t *testing.T) {
+		res, err := cli.Post(
+			targetURL+"/admin/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+				Request: httpfakeserver.Request{
+					Body: &httpfakeserver.BodyMatcher{Type: httpfakeserver.BodyMatcherTypeRegexp, Value: "("},
+				},
+			})),
+		)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "invalid mock: request: body: value is not valid regexp: error parsing regexp: missing closing ): `(`", string(body))
+	})
+}
+
+func TestRequests(t *testing.T) {
+	cli := http.DefaultClient
+	ctx := context.Background()
+	baseURLAdmin := targetURL + "/admin"
+
+	// setup: モックとリクエストの記録をクリアする
+	req, err := http.NewRequest(http.MethodDelete, targetURL+"/admin/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, httpfakeserver.DeleteRequests(ctx, cli, baseURLAdmin))
+
+	m := httpfakeserver.Mock{
+		Request: httpfakeserver.Request{
+			Method: "PUT",
+			Path:   "/repos/o/r/pulls/1/merge",
+			Query:  url.Values{"merge_method": {"squash"}},
+		},
+		Response: httpfakeserver.Response{Status: http.StatusOK},
+	}
+	res, err = cli.Post(
+		targetURL+"/admin/cases", "application/json",
+		bytes.NewBuffer(mustJSONMarshal(t, m)),
+	)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	// 記録されるリクエストを送る
+	req, err = http.NewRequest(http.MethodPut, targetURL+"/repos/o/r/pulls/1/merge?merge_method=squash", bytes.NewBufferString(`{"sha":"abc"}`))
+	require.NoError(t, err)
+	req.Header.Set("X-Custom", "val1")
+	res, err = cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	res, err = cli.Get(targetURL + "/abcde")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotImplemented, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	t.Run("all requests", func(t *testing.T) {
+		actual, err := httpfakeserver.GetRequests(ctx, cli, baseURLAdmin, httpfakeserver.RequestsFilter{})
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+
+		assert.Equal(t, "PUT", actual[0].Method)
+		assert.Equal(t, "/repos/o/r/pulls/1/merge?merge_method=squash", actual[0].URL)
+		assert.Equal(t, "val1", actual[0].Header.Get("X-Custom"))
+		assert.Equal(t, `{"sha":"abc"}`, actual[0].Body)
+		assert.Equal(t, m.ID(), actual[0].MockID)
+		assert.False(t, actual[0].ReceivedAt.IsZero())
+
+		assert.Equal(t, "/abcde", actual[1].Path())
+		assert.Equal(t, httpfakeserver.MockIDUnmatched, actual[1].MockID)
+	})
+
+	t.Run("filtered requests", func(t *testing.T) {
+		actual, err := httpfakeserver.GetRequests(ctx, cli, baseURLAdmin, httpfakeserver.RequestsFilter{
+			Method: "PUT",
+			Path:   "/repos/o/r/pulls/1/merge",
+			Query:  url.Values{"merge_method": {"squash"}},
+		})
+		require.NoError(t, err)
+		assert.Len(t, actual, 1)
+
+		actual, err = httpfakeserver.GetRequests(ctx, cli, baseURLAdmin, httpfakeserver.RequestsFilter{
+			MockID: httpfakeserver.MockIDUnmatched,
+		})
+		require.NoError(t, err)
+		require.Len(t, actual, 1)
+		assert.Equal(t, "/abcde", actual[0].URL)
+	})
+
+	t.Run("delete requests", func(t *testing.T) {
+		require.NoError(t, httpfakeserver.DeleteRequests(ctx, cli, baseURLAdmin))
+
+		actual, err := httpfakeserver.GetRequests(ctx, cli, baseURLAdmin, httpfakeserver.RequestsFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
+
+func TestNamespaces(t *testing.T) {
+	cli := http.DefaultClient
+	ctx := context.Background()
+	baseURLAdmin := targetURL + "/admin"
+
+	// setup: 共有namespaceのモックをクリアし、フォールバック用のモックを登録する
+	req, err := http.NewRequest(http.MethodDelete, baseURLAdmin+"/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	res, err = cli.Post(
+		baseURLAdmin+"/cases", "application/json",
+		bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+			Request:  httpfakeserver.Request{Method: "GET", Path: "/shared"},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "shared"},
+		})),
+	)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	for _, testID := range []string{uuid.NewString(), uuid.NewString()} {
+		t.Run(testID, func(t *testing.T) {
+			t.Parallel()
+
+			baseURLAdminForTest := httpfakeserver.BaseURLAdminForTest(baseURLAdmin, testID)
+			cliForTest := &http.Client{
+				Transport: e2ehelpers.NewRoundTripperForE2E(testID, http.DefaultTransport, "http", targetURL[len("http://"):]),
+			}
+
+			res, err := cli.Post(
+				baseURLAdminForTest+"/cases", "application/json",
+				bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+					Request:  httpfakeserver.Request{Method: "GET", Path: "/ns"},
+					Response: httpfakeserver.Response{Status: http.StatusOK, Body: testID},
+				})),
+			)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+
+			// 自分のnamespaceのモックが使われる
+			res, err = cliForTest.Get("http://example.com/ns")
+			require.NoError(t, err)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, testID, string(body))
+
+			// 共有namespaceのモックにフォールバックする
+			res, err = cliForTest.Get("http://example.com/shared")
+			require.NoError(t, err)
+			body, err = io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, "shared", string(body))
+
+			// ヘッダーでもnamespaceを指定できる
+			req, err := http.NewRequest(http.MethodGet, baseURLAdmin+"/cases", nil)
+			require.NoError(t, err)
+			req.Header.Set(httpfakeserver.HeaderNameTestID, testID)
+			res, err = cli.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close() //nolint:errcheck
+			mocks := mustJSONUnmarshalFromHTTPResponse[httpfakeserver.Mocks](t, res)
+			require.Len(t, *mocks, 1)
+			assert.Equal(t, testID, (*mocks)[0].Response.Body)
+
+			// リクエストの記録もnamespaceごとに分かれる
+			requests, err := httpfakeserver.GetRequests(ctx, cli, baseURLAdminForTest, httpfakeserver.RequestsFilter{})
+			require.NoError(t, err)
+			require.Len(t, requests, 2)
+			assert.Equal(t, testID, requests[0].TestID)
+			assert.Equal(t, "/ns", requests[0].Path())
+			assert.Equal(t, "/shared", requests[1].Path())
+
+			require.NoError(t, httpfakeserver.DeleteRequests(ctx, cli, baseURLAdminForTest))
+			req, err = http.NewRequest(http.MethodDelete, baseURLAdminForTest+"/cases", nil)
+			require.NoError(t, err)
+			res, err = cli.Do(req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusNoContent, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+
+			res, err = cliForTest.Get("http://example.com/ns")
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, http.StatusNotImplemented, res.StatusCode)
+		})
+	}
+}
+
+func TestSequencedResponses(t *testing.T) {
+	cli := http.DefaultClient
+
+	// setup: モックをクリアする
+	req, err := http.NewRequest(http.MethodDelete, targetURL+"/admin/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	mocks := []httpfakeserver.Mock{
+		{
+			Request: httpfakeserver.Request{Method: "GET", Path: "/repos/o/r/pulls/1"},
+			Responses: []httpfakeserver.Response{
+				{Status: http.StatusOK, Body: `{"mergeable":null}`},
+				{Status: http.StatusOK, Body: `{"mergeable":true}`},
+			},
+		},
+		{
+			Request: httpfakeserver.Request{Method: "GET", Path: "/repos/o/r/pulls/2"},
+			Responses: []httpfakeserver.Response{
+				{Status: http.StatusOK, Body: `{"mergeable":true}`},
+			},
+			WhenExhausted: httpfakeserver.WhenExhaustedFail,
+		},
+		{
+			Request:  httpfakeserver.Request{Method: "PUT", Path: "/repos/o/r/pulls/1/merge"},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: `{"merged":true}`},
+			Times:    1,
+		},
+	}
+	for _, m := range mocks {
+		res, err = cli.Post(
+			targetURL+"/admin/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, m)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	}
+
+	call := func(t *testing.T, method string, path string) (int, string) {
+		req, err := http.NewRequest(method, targetURL+path, nil)
+		require.NoError(t, err)
+		res, err := cli.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(body)
+	}
+
+	t.Run("responses are used in order and the last is repeated", func(t *testing.T) {
+		for _, want := range []string{`{"mergeable":null}`, `{"mergeable":true}`, `{"mergeable":true}`} {
+			status, body := call(t, http.MethodGet, "/repos/o/r/pulls/1")
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, want, body)
+		}
+	})
+
+	t.Run("fail after exhaustion", func(t *testing.T) {
+		status, _ := call(t, http.MethodGet, "/repos/o/r/pulls/2")
+		assert.Equal(t, http.StatusOK, status)
+		status, body := call(t, http.MethodGet, "/repos/o/r/pulls/2")
+		assert.Equal(t, http.StatusNotImplemented, status)
+		assert.Equal(t, "responses of the matched case are exhausted", body)
+	})
+
+	t.Run("times", func(t *testing.T) {
+		status, _ := call(t, http.MethodPut, "/repos/o/r/pulls/1/merge")
+		assert.Equal(t, http.StatusOK, status)
+		status, body := call(t, http.MethodPut, "/repos/o/r/pulls/1/merge")
+		assert.Equal(t, http.StatusNotImplemented, status)
+		assert.Equal(t, "no matched to cases", body)
+	})
+
+	t.Run("consumption counts are reported", func(t *testing.T) {
+		res, err := cli.Get(targetURL + "/admin/cases")
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+
+		actual := mustJSONUnmarshalFromHTTPResponse[httpfakeserver.Mocks](t, res)
+		consumed := map[string]int{}
+		for _, m := range *actual {
+			consumed[m.Request.Path] = m.Consumed
+		}
+		assert.Equal(t, map[string]int{
+			"/repos/o/r/pulls/1":       3,
+			"/repos/o/r/pulls/2":       2,
+			"/repos/o/r/pulls/1/merge": 1,
+		}, consumed)
+	})
+
+	t.Run("invalid mock returns 400", func(t *testing.T) {
+		res, err := cli.Post(
+			targetURL+"/admin/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{WhenExhausted: "foo"})),
+		)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `invalid mock: unknown whenExhausted: "foo"`, string(body))
+	})
+}
+
+func TestPathMatchers(t *testing.T) {
+	cli := http.DefaultClient
+	ctx := context.Background()
+	baseURLAdmin := targetURL + "/admin"
+
+	// setup: モックとリクエストの記録をクリアする
+	req, err := http.NewRequest(http.MethodDelete, baseURLAdmin+"/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, httpfakeserver.DeleteRequests(ctx, cli, baseURLAdmin))
+
+	mocks := []httpfakeserver.Mock{
+		{
+			Request:  httpfakeserver.Request{Method: "GET", Path: "/repos/o/r/pulls/1"},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "exact"},
+		},
+		{
+			Request: httpfakeserver.Request{
+				Method:      "GET",
+				PathMatcher: httpfakeserver.PathMatcherTypePattern,
+				Path:        "/repos/{owner}/{repo}/pulls/{number}",
+			},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "pattern"},
+		},
+		{
+			Request: httpfakeserver.Request{
+				Method:      "GET",
+				PathMatcher: httpfakeserver.PathMatcherTypeGlob,
+				Path:        "/repos/*/*/pulls",
+			},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "glob"},
+		},
+		{
+			Request: httpfakeserver.Request{
+				Method:      "GET",
+				PathMatcher: httpfakeserver.PathMatcherTypeRegexp,
+				Path:        `/repos/.+`,
+			},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "regexp"},
+		},
+	}
+	for _, m := range mocks {
+		res, err = cli.Post(
+			baseURLAdmin+"/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, m)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	}
+
+	testCases := []struct {
+		path string
+		want string
+	}{
+		{path: "/repos/o/r/pulls/1", want: "exact"},
+		{path: "/repos/o/r/pulls/2", want: "pattern"},
+		{path: "/repos/o/r/pulls", want: "glob"},
+		{path: "/repos/o/r/issues", want: "regexp"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.path, func(t *testing.T) {
+			res, err := cli.Get(targetURL + tC.path)
+			require.NoError(t, err)
+			defer res.Body.Close() //nolint:errcheck
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tC.want, string(body))
+		})
+	}
+
+	t.Run("captured path variables are recorded", func(t *testing.T) {
+		requests, err := httpfakeserver.GetRequests(ctx, cli, baseURLAdmin, httpfakeserver.RequestsFilter{
+			Path: "/repos/o/r/pulls/2",
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, map[string]string{"owner": "o", "repo": "r", "number": "2"}, requests[0].PathVars)
+	})
+
+	t.Run("invalid pattern returns 400", func(t *testing.T) {
+		res, err := cli.Post(
+			baseURLAdmin+"/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+				Request: httpfakeserver.Request{PathMatcher: httpfakeserver.PathMatcherTypePattern, Path: "/a{b}"},
+			})),
+		)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "invalid mock: request: path: variable must be a whole segment: /a{b}", string(body))
+	})
+}
+
+func TestResponseTemplate(t *testing.T) {
+	cli := http.DefaultClient
+	baseURLAdmin := targetURL + "/admin"
+
+	// setup: モックをクリアする
+	req, err := http.NewRequest(http.MethodDelete, baseURLAdmin+"/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	// GitHub の CreateComment のように、投稿されたコメントと生成した ID を返す
+	res, err = cli.Post(
+		baseURLAdmin+"/cases", "application/json",
+		bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+			Request: httpfakeserver.Request{
+				Method:      http.MethodPost,
+				PathMatcher: httpfakeserver.PathMatcherTypePattern,
+				Path:        "/repos/{owner}/{repo}/issues/{number}/comments",
+			},
+			Response: httpfakeserver.Response{
+				Status:   http.StatusCreated,
+				Header:   http.Header{"Location": {"/repos/{{.PathVars.owner}}/{{.PathVars.repo}}/issues/comments/{{.Header.Get \"X-Comment-Id\"}}"}},
+				Body:     `{"node_id":"{{uuid}}","issue_url":"/repos/{{.PathVars.owner}}/{{.PathVars.repo}}/issues/{{.PathVars.number}}","body":{{json .Body.body}}}`,
+				Template: true,
+			},
+		})),
+	)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	req, err = http.NewRequest(
+		http.MethodPost, targetURL+"/repos/o/r/issues/3/comments",
+		bytes.NewBufferString(`{"body":"LGTM \"!\""}`),
+	)
+	require.NoError(t, err)
+	req.Header.Set("X-Comment-Id", "10")
+	res, err = cli.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close() //nolint:errcheck
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "/repos/o/r/issues/comments/10", res.Header.Get("Location"))
+	actual := map[string]string{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&actual))
+	assert.Equal(t, "/repos/o/r/issues/3", actual["issue_url"])
+	assert.Equal(t, `LGTM "!"`, actual["body"])
+	assert.Regexp(t, `^[0-9a-f-]{36}$`, actual["node_id"])
+
+	t.Run("invalid template returns 400", func(t *testing.T) {
+		res, err := cli.Post(
+			baseURLAdmin+"/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+				Response: httpfakeserver.Response{Body: "{{.Body", Template: true},
+			})),
+		)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestFaults(t *testing.T) {
+	cli := http.DefaultClient
+	baseURLAdmin := targetURL + "/admin"
+
+	// setup: モックをクリアする
+	req, err := http.NewRequest(http.MethodDelete, baseURLAdmin+"/cases", nil)
+	require.NoError(t, err)
+	res, err := cli.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	mocks := []httpfakeserver.Mock{
+		{
+			Request:  httpfakeserver.Request{Method: http.MethodGet, Path: "/slow"},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "slow", Fault: &httpfakeserver.Fault{DelayMs: 500}},
+		},
+		{
+			Request:  httpfakeserver.Request{Method: http.MethodGet, Path: "/drop"},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Fault: &httpfakeserver.Fault{DropConnection: true}},
+		},
+	}
+	for _, m := range mocks {
+		res, err = cli.Post(
+			baseURLAdmin+"/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, m)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	}
+
+	t.Run("client times out", func(t *testing.T) {
+		cli := http.Client{Timeout: 100 * time.Millisecond}
+		_, err := cli.Get(targetURL + "/slow")
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "Client.Timeout exceeded")
+	})
+
+	t.Run("connection is dropped", func(t *testing.T) {
+		_, err := cli.Get(targetURL + "/drop")
+		assert.ErrorContains(t, err, "EOF")
+	})
+
+	t.Run("invalid fault returns 400", func(t *testing.T) {
+		res, err := cli.Post(
+			baseURLAdmin+"/cases", "application/json",
+			bytes.NewBuffer(mustJSONMarshal(t, httpfakeserver.Mock{
+				Response: httpfakeserver.Response{Fault: &httpfakeserver.Fault{DelayMs: -1}},
+			})),
+		)
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "invalid mock: response: fault: delayMs must not be negative: -1", string(body))
+	})
+}
+
+func TestMocksDir(t *testing.T) {
+	port, err := strconv.Atoi(os.Getenv("PORT"))
+	require.NoError(t, err)
+	// 他のテストが使うサーバーとは別のポートでサーバーを起動する
+	port++
+	targetURL := fmt.Sprintf("http://localhost:%d", port)
+
+	t.Run("mocks are loaded and reloaded", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "github.yaml"), []byte(`
+- request:
+    method: GET
+    path: /repos/o/r/pulls/1
+  response:
+    status: 200
+    body: before
+`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a mocks file"), 0644))
+
+		ctx := context.Background()
+		shutdown, ok := e2ehelpers.RunServer(
+			ctx,
+			filePathServerBin,
+			&e2ehelpers.RunServerInput{
+				Envs: []string{
+					fmt.Sprintf("PORT=%d", port),
+					"MOCKS_DIR=" + dir,
+					"WATCH_MOCKS_DIR=true",
+				},
+			},
+			func() error {
+				return e2ehelpers.CheckHTTPServerHealth(ctx, targetURL+"/admin/health")
+			},
+		)
+		defer shutdown() // nolint:errcheck
+		require.True(t, ok)
+
+		get := func() string {
+			res, err := http.Get(targetURL + "/repos/o/r/pulls/1")
+			if err != nil {
+				return err.Error()
+			}
+			defer res.Body.Close() //nolint:errcheck
+			body, _ := io.ReadAll(res.Body)
+			return string(body)
+		}
+		assert.Equal(t, "before", get())
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "github.json"), []byte(`[
+  {
+    "request": {"method": "GET", "path": "/repos/o/r/pulls/1"},
+    "response": {"status": 200, "body": "after"}
+  }
+]`), 0644))
+		require.NoError(t, os.Remove(filepath.Join(dir, "github.yaml")))
+		assert.Eventually(t, func() bool { return get() == "after" }, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("invalid mocks file refuses to start", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`[
+  {"request": {"path": "/a", "pathMatcher": "foo"}}
+]`), 0644))
+
+		cmd := exec.Command(filePathServerBin)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("PORT=%d", port), "MOCKS_DIR="+dir)
+		stderr := bytes.Buffer{}
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+
+		exitErr := &exec.ExitError{}
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode())
+		assert.Contains(
+			t,
+			stderr.String(),
+			filepath.Join(dir, "invalid.json")+`: [0]: request: path: unknown pathMatcher: "foo"`,
+		)
+	})
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	baseURL, err := url.Parse(targetURL)
+	require.NoError(t, err)
+	testID := uuid.NewString()
+	admin := httpfakeserver.NewClient(baseURL, "/admin", http.DefaultClient).ForTest(testID)
+	cli := http.Client{
+		Transport: e2ehelpers.NewRoundTripperForE2E(testID, http.DefaultTransport, "http", targetURL[len("http://"):]),
+	}
+
+	require.NoError(t, admin.Reset(ctx))
+	require.NoError(t, admin.AddMocks(ctx, httpfakeserver.Mocks{
+		{
+			Request:  httpfakeserver.Request{Method: http.MethodGet, Path: "/foo"},
+			Response: httpfakeserver.Response{Status: http.StatusOK, Body: "foo"},
+		},
+		{
+			Request:  httpfakeserver.Request{Method: http.MethodPost, Path: "/bar"},
+			Response: httpfakeserver.Response{Status: http.StatusCreated},
+		},
+	}))
+
+	mocks, err := admin.ListMocks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, mocks, 2)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		res, err := cli.Get(targetURL + "/foo")
+		if err == nil {
+			res.Body.Close() //nolint:errcheck
+		}
+	}()
+	recorded, err := admin.WaitForRequest(ctx, httpfakeserver.RequestsFilter{Method: http.MethodGet, Path: "/foo"})
+	require.NoError(t, err)
+	assert.Equal(t, mocks[0].ID(), recorded.MockID)
+
+	t.Run("invalid mock returns APIError", func(t *testing.T) {
+		err := admin.AddMock(ctx, httpfakeserver.Mock{
+			Request: httpfakeserver.Request{PathMatcher: "foo"},
+		})
+		apiErr := &httpfakeserver.APIError{}
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, `invalid mock: request: path: unknown pathMatcher: "foo"`, apiErr.Message)
+	})
+
+	require.NoError(t, admin.Reset(ctx))
+	mocks, err = admin.ListMocks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, mocks)
+	requests, err := admin.Requests(ctx, httpfakeserver.RequestsFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+}
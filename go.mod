@@ -7,6 +7,7 @@ toolchain go1.26.1
 require (
 	github.com/Masterminds/semver/v3 v3.4.0
 	github.com/air-verse/air v1.64.5
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/golang-cz/devslog v0.0.15
 	github.com/google/go-github/v67 v67.0.0
 	github.com/google/go-github/v68 v68.0.0
@@ -14,9 +15,11 @@ require (
 	github.com/hashicorp/hcl/v2 v2.24.0
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/playwright-community/playwright-go v0.5700.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/smocker-dev/smocker v0.0.0-20240320000158-310c15349c41
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/pkgsite v0.0.0-20250214205047-dd488e5da97a
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -29,7 +32,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/deckarep/golang-set/v2 v2.8.0 // indirect
 	github.com/fatih/color v1.18.0 // indirect
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/go-jose/go-jose/v3 v3.0.4 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
 	github.com/go-test/deep v1.0.8 // indirect
@@ -45,7 +47,6 @@ require (
 	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
 	github.com/pelletier/go-toml v1.9.5 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/sirupsen/logrus v1.9.3 // indirect
 	github.com/smartystreets/assertions v1.0.1 // indirect
 	github.com/spf13/afero v1.14.0 // indirect
@@ -61,7 +62,6 @@ require (
 	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/tools v0.36.0 // indirect
 	google.golang.org/protobuf v1.36.8 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	rsc.io/markdown v0.0.0-20231214224604-88bb533a6020 // indirect
 )
 
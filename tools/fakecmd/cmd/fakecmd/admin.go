@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+const commandNameAdmin = "fakecmd"
+
+var usageStringAdmin = `Usage: fakecmd admin <subcommand> [flags]
+
+Subcommands:
+  init     initialize a fake command in a directory
+  history  print recorded invocations of a fake command as JSON
+  reset    remove recorded invocations of a fake command
+  verify   exit not zero if some behaviors of a fake command have not been used
+  schema   print the JSON Schema of the behaviors file
+`
+
+// isAdmin reports whether the process is invoked as "fakecmd admin ...".
+// A cloned fake command is never named fakecmd, so that its argv is not taken as admin subcommands.
+func isAdmin(args []string) bool {
+	return len(args) > 1 &&
+		filepath.Base(args[0]) == commandNameAdmin &&
+		args[1] == "admin"
+}
+
+func adminMain(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) <= 0 {
+		fmt.Fprint(stderr, usageStringAdmin) //nolint:errcheck
+		return 1
+	}
+
+	var err error
+	switch args[0] {
+	case "init":
+		err = adminInit(args[1:], stderr)
+	case "history":
+		err = adminHistory(args[1:], stdout, stderr)
+	case "reset":
+		err = adminReset(args[1:], stderr)
+	case "verify":
+		err = adminVerify(args[1:], stdout, stderr)
+	case "schema":
+		err = adminSchema(stdout)
+	default:
+		fmt.Fprintf(stderr, "unknown subcommand: %s\n", args[0]) //nolint:errcheck
+		fmt.Fprint(stderr, usageStringAdmin)                     //nolint:errcheck
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err) //nolint:errcheck
+		return 1
+	}
+
+	return 0
+}
+
+func newAdminFlagSet(name string, stderr io.Writer, dirPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(fmt.Sprintf("fakecmd admin %s", name), flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(dirPath, "dir", "", "directory path of the fake command")
+	return fs
+}
+
+func parseAdminFlags(fs *flag.FlagSet, args []string, dirPath *string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dirPath == "" {
+		return fmt.Errorf("-dir is required")
+	}
+	return nil
+}
+
+func adminInit(args []string, stderr io.Writer) error {
+	var dirPath string
+	var filePathBehaviors string
+	var envAllowlist string
+	var lockTimeout time.Duration
+	var captureStdin bool
+	var force bool
+	fs := newAdminFlagSet("init", stderr, &dirPath)
+	fs.StringVar(&filePathBehaviors, "behaviors", "", "file path of behaviors JSON")
+	fs.StringVar(&envAllowlist, "env-allowlist", "", "comma separated names of environment variables recorded in histories")
+	fs.DurationVar(&lockTimeout, "lock-timeout", 0, "how long an invocation waits for other invocations to update the state")
+	fs.BoolVar(&captureStdin, "capture-stdin", false, "record stdin in histories even if the used behavior does not read it")
+	fs.BoolVar(&force, "force", false, "remove the directory before initialization")
+	if err := parseAdminFlags(fs, args, &dirPath); err != nil {
+		return err
+	}
+	if filePathBehaviors == "" {
+		return fmt.Errorf("-behaviors is required")
+	}
+
+	spec, err := domains.ReadSpecFile(filePathBehaviors)
+	if err != nil {
+		return err
+	}
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("invalid behaviors: %s:\n%w", filePathBehaviors, err)
+	}
+
+	filePathFakeCMD, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to os.Executable: %w", err)
+	}
+
+	opts := []domains.FakeCommandOption{}
+	if spec.DefaultBehavior != nil {
+		opts = append(opts, domains.WithDefaultBehavior(*spec.DefaultBehavior))
+	}
+	opts = append(
+		opts,
+		domains.WithWhenExhausted(spec.WhenExhausted),
+		domains.WithLockTimeout(lockTimeout),
+	)
+	if envAllowlist != "" {
+		opts = append(opts, domains.WithEnvAllowlist(strings.Split(envAllowlist, ",")...))
+	}
+	if captureStdin {
+		opts = append(opts, domains.WithCaptureStdin())
+	}
+
+	fcmd := domains.NewFakeCommand(
+		filePathFakeCMD,
+		domains.DirPathFakeCommand(dirPath),
+		spec.Behaviors,
+		opts...,
+	)
+	return fcmd.Init(force)
+}
+
+func adminHistory(args []string, stdout io.Writer, stderr io.Writer) error {
+	var dirPath string
+	fs := newAdminFlagSet("history", stderr, &dirPath)
+	if err := parseAdminFlags(fs, args, &dirPath); err != nil {
+		return err
+	}
+
+	fcmd, err := domains.OpenFakeCommand(domains.DirPathFakeCommand(dirPath))
+	if err != nil {
+		return err
+	}
+
+	histories, err := fcmd.Histories()
+	if err != nil {
+		return err
+	}
+	if histories == nil {
+		histories = domains.ExecutedHistories{}
+	}
+
+	b, err := json.MarshalIndent(histories, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to json.MarshalIndent: %w", err)
+	}
+
+	fmt.Fprintln(stdout, string(b)) //nolint:errcheck
+	return nil
+}
+
+func adminReset(args []string, stderr io.Writer) error {
+	var dirPath string
+	fs := newAdminFlagSet("reset", stderr, &dirPath)
+	if err := parseAdminFlags(fs, args, &dirPath); err != nil {
+		return err
+	}
+
+	fcmd, err := domains.OpenFakeCommand(domains.DirPathFakeCommand(dirPath))
+	if err != nil {
+		return err
+	}
+
+	return fcmd.Reset()
+}
+
+func adminVerify(args []string, stdout io.Writer, stderr io.Writer) error {
+	var dirPath string
+	fs := newAdminFlagSet("verify", stderr, &dirPath)
+	if err := parseAdminFlags(fs, args, &dirPath); err != nil {
+		return err
+	}
+
+	fcmd, err := domains.OpenFakeCommand(domains.DirPathFakeCommand(dirPath))
+	if err != nil {
+		return err
+	}
+
+	if err := fcmd.Verify(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, "all behaviors are consumed") //nolint:errcheck
+	return nil
+}
+
+func adminSchema(stdout io.Writer) error {
+	b, err := domains.SpecJSONSchema()
+	if err != nil {
+		return err
+	}
+
+	if _, err := stdout.Write(b); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
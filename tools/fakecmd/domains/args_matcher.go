@@ -0,0 +1,99 @@
+package domains
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ArgsMatcherType int
+
+const (
+	// ArgsMatcherTypeExact matches when argv equals Args.
+	ArgsMatcherTypeExact ArgsMatcherType = iota + 1
+	// ArgsMatcherTypePrefix matches when argv starts with Args.
+	ArgsMatcherTypePrefix
+	// ArgsMatcherTypeRegexp matches when each argument of argv matches the regular expression at the same position of Args.
+	// Each regular expression must match the whole argument.
+	ArgsMatcherTypeRegexp
+)
+
+func (t ArgsMatcherType) String() string {
+	switch t {
+	case ArgsMatcherTypeExact:
+		return "exact"
+	case ArgsMatcherTypePrefix:
+		return "prefix"
+	case ArgsMatcherTypeRegexp:
+		return "regexp"
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
+// ArgsMatcher matches argv (excluding the command name) of an invocation.
+type ArgsMatcher struct {
+	Type ArgsMatcherType
+	Args []string
+}
+
+func (t *ArgsMatcher) Match(args []string) (bool, error) {
+	switch t.Type {
+	case ArgsMatcherTypeExact:
+		if len(args) != len(t.Args) {
+			return false, nil
+		}
+		for i := range t.Args {
+			if args[i] != t.Args[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+	case ArgsMatcherTypePrefix:
+		if len(args) < len(t.Args) {
+			return false, nil
+		}
+		for i := range t.Args {
+			if args[i] != t.Args[i] {
+				return false, nil
+			}
+		}
+		return true, nil
+	case ArgsMatcherTypeRegexp:
+		if len(args) != len(t.Args) {
+			return false, nil
+		}
+		for i := range t.Args {
+			re, err := compileArgRegexp(t.Args[i])
+			if err != nil {
+				return false, fmt.Errorf("failed to regexp.Compile: %s: %w", t.Args[i], err)
+			}
+			if !re.MatchString(args[i]) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown args matcher type: %d", t.Type)
+}
+
+// compileArgRegexp compiles expr so that it matches only a whole argument.
+// expr is compiled as it is first so that errors refer to expr.
+func compileArgRegexp(expr string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(`^(?:` + expr + `)$`)
+}
+
+func (t *ArgsMatcher) String() string {
+	return fmt.Sprintf("%s %s", t.Type, FormatArgs(t.Args))
+}
+
+// FormatArgs formats argv like ["plan" "-no-color"].
+func FormatArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, fmt.Sprintf("%q", arg))
+	}
+	return "[" + strings.Join(quoted, " ") + "]"
+}
@@ -0,0 +1,123 @@
+package domains_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestArgsMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		matcher domains.ArgsMatcher
+		args    []string
+		want    bool
+		wantErr bool
+	}{
+		{
+			desc:    "exact - matched",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: []string{"plan", "-no-color"}},
+			args:    []string{"plan", "-no-color"},
+			want:    true,
+		},
+		{
+			desc:    "exact - length differs",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: []string{"plan"}},
+			args:    []string{"plan", "-no-color"},
+			want:    false,
+		},
+		{
+			desc:    "exact - value differs",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: []string{"plan"}},
+			args:    []string{"init"},
+			want:    false,
+		},
+		{
+			desc:    "prefix - matched",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypePrefix, Args: []string{"plan"}},
+			args:    []string{"plan", "-no-color"},
+			want:    true,
+		},
+		{
+			desc:    "prefix - args is shorter",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypePrefix, Args: []string{"plan", "-no-color"}},
+			args:    []string{"plan"},
+			want:    false,
+		},
+		{
+			desc:    "regexp - matched",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"^-chdir=.+$", "plan"}},
+			args:    []string{"-chdir=/x", "plan"},
+			want:    true,
+		},
+		{
+			desc:    "regexp - not matched",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"^-chdir=.+$", "plan"}},
+			args:    []string{"-chdir=", "plan"},
+			want:    false,
+		},
+		{
+			desc:    "regexp - substring does not match",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"plan"}},
+			args:    []string{"-plan-file"},
+			want:    false,
+		},
+		{
+			desc:    "regexp - alternation matches whole argument",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"plan|apply"}},
+			args:    []string{"explain"},
+			want:    false,
+		},
+		{
+			desc:    "regexp - invalid expression",
+			matcher: domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"("}},
+			args:    []string{"a"},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown type",
+			matcher: domains.ArgsMatcher{},
+			args:    []string{},
+			wantErr: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := tC.matcher.Match(tC.args)
+			if tC.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.want, actual)
+		})
+	}
+}
+
+func TestBehaviors_FindUnused(t *testing.T) {
+	t.Parallel()
+
+	behaviors := domains.Behaviors{
+		{ArgsMatcher: &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: []string{"init"}}},
+		{ArgsMatcher: &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: []string{"plan"}}},
+		{},
+	}
+
+	i, err := behaviors.FindUnused([]string{"plan"}, map[int]bool{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	i, err = behaviors.FindUnused([]string{"plan"}, map[int]bool{1: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, i)
+
+	i, err = behaviors.FindUnused([]string{"plan"}, map[int]bool{1: true, 2: true})
+	require.NoError(t, err)
+	assert.Equal(t, -1, i)
+}
@@ -0,0 +1,125 @@
+package domains
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tHelper interface {
+	Helper()
+}
+
+// AssertCalledTimes asserts that the fake command has been called n times.
+func (t *FakeCommand) AssertCalledTimes(tt assert.TestingT, n int) bool {
+	if h, ok := tt.(tHelper); ok {
+		h.Helper()
+	}
+
+	histories, ok := t.assertHistories(tt)
+	if !ok {
+		return false
+	}
+
+	return assert.Equal(
+		tt, n, len(histories),
+		"unexpected number of calls: recorded argv are\n%s", describeHistories(histories),
+	)
+}
+
+// AssertNotCalled asserts that the fake command has never been called.
+func (t *FakeCommand) AssertNotCalled(tt assert.TestingT) bool {
+	if h, ok := tt.(tHelper); ok {
+		h.Helper()
+	}
+
+	histories, ok := t.assertHistories(tt)
+	if !ok {
+		return false
+	}
+
+	if len(histories) > 0 {
+		return assert.Fail(
+			tt, "fake command has been called",
+			"recorded argv are\n%s", describeHistories(histories),
+		)
+	}
+
+	return true
+}
+
+// AssertCalledWith asserts that argv of the i-th (0-origin) call of the fake command equals args.
+func (t *FakeCommand) AssertCalledWith(tt assert.TestingT, i int, args ...string) bool {
+	if h, ok := tt.(tHelper); ok {
+		h.Helper()
+	}
+
+	histories, ok := t.assertHistories(tt)
+	if !ok {
+		return false
+	}
+
+	if i < 0 || len(histories) <= i {
+		return assert.Fail(
+			tt, fmt.Sprintf("call %d does not exist", i),
+			"recorded argv are\n%s", describeHistories(histories),
+		)
+	}
+
+	if args == nil {
+		args = []string{}
+	}
+	actual := histories[i].Args
+	if actual == nil {
+		actual = []string{}
+	}
+
+	return assert.Equal(tt, args, actual, "unexpected argv of call %d", i)
+}
+
+// AssertAllBehaviorsConsumed asserts that every behavior of the fake command has been used.
+func (t *FakeCommand) AssertAllBehaviorsConsumed(tt assert.TestingT) bool {
+	if h, ok := tt.(tHelper); ok {
+		h.Helper()
+	}
+
+	histories, ok := t.assertHistories(tt)
+	if !ok {
+		return false
+	}
+
+	if err := t.Verify(); err != nil {
+		return assert.Fail(
+			tt, "some behaviors are not consumed",
+			"%s\nrecorded argv are\n%s",
+			err, describeHistories(histories),
+		)
+	}
+
+	return true
+}
+
+func (t *FakeCommand) assertHistories(tt assert.TestingT) (ExecutedHistories, bool) {
+	if h, ok := tt.(tHelper); ok {
+		h.Helper()
+	}
+
+	histories, err := t.Histories()
+	if !assert.NoError(tt, err) {
+		return nil, false
+	}
+	return histories, true
+}
+
+func describeHistories(histories ExecutedHistories) string {
+	if len(histories) <= 0 {
+		return "(no calls)"
+	}
+
+	lines := []string{}
+	for i, h := range histories {
+		lines = append(lines, fmt.Sprintf("calls[%d]: %s (behaviors[%d])", i, FormatArgs(h.Args), h.BehaviorIndex))
+	}
+	return strings.Join(lines, "\n")
+}
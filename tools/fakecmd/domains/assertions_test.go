@@ -0,0 +1,125 @@
+package domains_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/suzuito/sandbox2-common-go/libs/e2ehelpers"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+type fakeTestingT struct {
+	failed bool
+}
+
+func (t *fakeTestingT) Errorf(format string, args ...any) {
+	t.failed = true
+}
+
+func TestFakeCommand_Assertions(t *testing.T) {
+	t.Parallel()
+
+	dirPath := domains.DirPathFakeCommand(fmt.Sprintf("/tmp/%s", uuid.NewString()))
+	e2ehelpers.MustMkdir(dirPath.String())
+	t.Cleanup(func() {
+		os.RemoveAll(dirPath.String()) //nolint:errcheck
+	})
+
+	fcmd := domains.NewFakeCommand("", dirPath, domains.Behaviors{{}, {}})
+
+	testCases := []struct {
+		desc       string
+		histories  domains.ExecutedHistories
+		assertFunc func(tt assert.TestingT) bool
+		want       bool
+	}{
+		{
+			desc:       "AssertNotCalled - ok",
+			assertFunc: fcmd.AssertNotCalled,
+			want:       true,
+		},
+		{
+			desc:       "AssertNotCalled - ng",
+			histories:  domains.ExecutedHistories{{Args: []string{"init"}}},
+			assertFunc: fcmd.AssertNotCalled,
+			want:       false,
+		},
+		{
+			desc:      "AssertCalledTimes - ok",
+			histories: domains.ExecutedHistories{{BehaviorIndex: 0}, {BehaviorIndex: 1}},
+			assertFunc: func(tt assert.TestingT) bool {
+				return fcmd.AssertCalledTimes(tt, 2)
+			},
+			want: true,
+		},
+		{
+			desc:      "AssertCalledTimes - ng",
+			histories: domains.ExecutedHistories{{BehaviorIndex: 0}},
+			assertFunc: func(tt assert.TestingT) bool {
+				return fcmd.AssertCalledTimes(tt, 2)
+			},
+			want: false,
+		},
+		{
+			desc: "AssertCalledWith - ok",
+			histories: domains.ExecutedHistories{
+				{BehaviorIndex: 0, Args: []string{"init"}},
+				{BehaviorIndex: 1, Args: []string{"plan", "-no-color"}},
+			},
+			assertFunc: func(tt assert.TestingT) bool {
+				return fcmd.AssertCalledWith(tt, 1, "plan", "-no-color")
+			},
+			want: true,
+		},
+		{
+			desc:      "AssertCalledWith - ok - no args",
+			histories: domains.ExecutedHistories{{BehaviorIndex: 0}},
+			assertFunc: func(tt assert.TestingT) bool {
+				return fcmd.AssertCalledWith(tt, 0)
+			},
+			want: true,
+		},
+		{
+			desc:      "AssertCalledWith - ng - argv differs",
+			histories: domains.ExecutedHistories{{BehaviorIndex: 0, Args: []string{"init"}}},
+			assertFunc: func(tt assert.TestingT) bool {
+				return fcmd.AssertCalledWith(tt, 0, "plan")
+			},
+			want: false,
+		},
+		{
+			desc:      "AssertCalledWith - ng - call does not exist",
+			histories: domains.ExecutedHistories{{BehaviorIndex: 0, Args: []string{"init"}}},
+			assertFunc: func(tt assert.TestingT) bool {
+				return fcmd.AssertCalledWith(tt, 1, "init")
+			},
+			want: false,
+		},
+		{
+			desc:       "AssertAllBehaviorsConsumed - ok",
+			histories:  domains.ExecutedHistories{{BehaviorIndex: 1}, {BehaviorIndex: 0}},
+			assertFunc: fcmd.AssertAllBehaviorsConsumed,
+			want:       true,
+		},
+		{
+			desc:       "AssertAllBehaviorsConsumed - ng",
+			histories:  domains.ExecutedHistories{{BehaviorIndex: 1}},
+			assertFunc: fcmd.AssertAllBehaviorsConsumed,
+			want:       false,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			e2ehelpers.MustWriteJSONFile(dirPath.FilePathState(), domains.State{
+				ExecutedHistories: tC.histories,
+			})
+
+			tt := fakeTestingT{}
+			assert.Equal(t, tC.want, tC.assertFunc(&tt))
+			assert.Equal(t, !tC.want, tt.failed)
+		})
+	}
+}
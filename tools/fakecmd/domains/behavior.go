@@ -1,20 +1,154 @@
 package domains
 
+import (
+	"fmt"
+	"strings"
+)
+
 type BehaviorType int
 
 const (
 	BehaviorTypeStdoutStderrExitCode = iota + 1
+	BehaviorTypeScript
+	BehaviorTypePassthrough
+	BehaviorTypeSignal
+	BehaviorTypeStream
 )
 
+func (t BehaviorType) String() string {
+	switch t {
+	case BehaviorTypeStdoutStderrExitCode:
+		return "stdoutStderrExitCode"
+	case BehaviorTypeScript:
+		return "script"
+	case BehaviorTypePassthrough:
+		return "passthrough"
+	case BehaviorTypeSignal:
+		return "signal"
+	case BehaviorTypeStream:
+		return "stream"
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
 type Behavior struct {
-	Type                         BehaviorType
+	Type BehaviorType
+	// ArgsMatcher is optional. A behavior without ArgsMatcher matches any argv.
+	ArgsMatcher *ArgsMatcher
+	// StdinMatcher is optional. See [StdinMatcher].
+	StdinMatcher                 *StdinMatcher
 	BehaviorStdoutStderrExitCode *BehaviorStdoutStderrExitCode
+	BehaviorScript               *BehaviorScript
+	BehaviorPassthrough          *BehaviorPassthrough
+	BehaviorSignal               *BehaviorSignal
+	BehaviorStream               *BehaviorStream
+}
+
+func (t *Behavior) Match(args []string) (bool, error) {
+	if t.ArgsMatcher == nil {
+		return true, nil
+	}
+	return t.ArgsMatcher.Match(args)
+}
+
+// UsesStdin returns true if the behavior reads stdin: it has StdinMatcher, passes through,
+// copies stdin in a script or refers .Stdin in a template.
+// An invocation of the other behaviors does not read stdin so that it does not block
+// on stdin which is never closed.
+func (t *Behavior) UsesStdin() bool {
+	if t.StdinMatcher != nil {
+		return true
+	}
+	switch t.Type {
+	case BehaviorTypePassthrough:
+		return true
+	case BehaviorTypeStdoutStderrExitCode:
+		b := t.BehaviorStdoutStderrExitCode
+		return b != nil && b.Template && (strings.Contains(b.Stdout, ".Stdin") || strings.Contains(b.Stderr, ".Stdin"))
+	case BehaviorTypeScript:
+		if t.BehaviorScript == nil {
+			return false
+		}
+		for _, step := range t.BehaviorScript.Steps {
+			if step.Type == ScriptStepTypeCopyStdin {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type BehaviorStdoutStderrExitCode struct {
 	Stdout   string
 	Stderr   string
 	ExitCode uint8
+	// Template makes Stdout and Stderr text/template strings evaluated against [TemplateContext].
+	Template bool
+}
+
+func (t *BehaviorStdoutStderrExitCode) Run(inv *Invocation) (uint8, error) {
+	stdout, stderr := t.Stdout, t.Stderr
+	if t.Template {
+		data := NewTemplateContext(inv)
+
+		var err error
+		stdout, err = executeTemplate("Stdout", t.Stdout, data)
+		if err != nil {
+			return 0, err
+		}
+		stderr, err = executeTemplate("Stderr", t.Stderr, data)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	fmt.Fprint(inv.Stdout, stdout) //nolint:errcheck
+	fmt.Fprint(inv.Stderr, stderr) //nolint:errcheck
+	return t.ExitCode, nil
 }
 
 type Behaviors []Behavior
+
+// FindUnused returns the index of the first behavior which is not used yet and matches args.
+// It returns -1 if no behavior matches.
+func (t Behaviors) FindUnused(args []string, used map[int]bool) (int, error) {
+	for i := range t {
+		if used[i] {
+			continue
+		}
+		matched, err := t[i].Match(args)
+		if err != nil {
+			return -1, fmt.Errorf("behaviors[%d]: %w", i, err)
+		}
+		if matched {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// AllUsed reports whether every behavior has been used.
+func (t Behaviors) AllUsed(used map[int]bool) bool {
+	for i := range t {
+		if !used[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DescribeCandidates returns a human readable list of argv matchers of unused behaviors.
+func (t Behaviors) DescribeCandidates(used map[int]bool) string {
+	lines := []string{}
+	for i := range t {
+		if used[i] {
+			continue
+		}
+		matcher := "any"
+		if t[i].ArgsMatcher != nil {
+			matcher = t[i].ArgsMatcher.String()
+		}
+		lines = append(lines, fmt.Sprintf("behaviors[%d]: %s", i, matcher))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,97 @@
+package domains
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// BehaviorPassthrough runs the real binary with the same argv, env and stdin.
+// The observed output and exit code are appended to the recordings file as a new behavior.
+type BehaviorPassthrough struct {
+	// FilePathBin is the absolute path of the real binary.
+	// A bare name is rejected because PATH of the invocation may point to the fake command itself.
+	FilePathBin string
+}
+
+// Run runs the real binary and returns the observed behavior which replays the invocation.
+func (t *BehaviorPassthrough) Run(inv *Invocation) (*Behavior, error) {
+	stdout, stderr := bytes.NewBufferString(""), bytes.NewBufferString("")
+
+	cmd := exec.Command(t.FilePathBin, inv.Args...)
+	cmd.Env = inv.Env
+	cmd.Dir = inv.Cwd
+	cmd.Stdin = bytes.NewReader(inv.Stdin)
+	cmd.Stdout = io.MultiWriter(inv.Stdout, stdout)
+	cmd.Stderr = io.MultiWriter(inv.Stderr, stderr)
+	if err := cmd.Run(); err != nil {
+		var exiterr *exec.ExitError
+		if !errors.As(err, &exiterr) {
+			return nil, fmt.Errorf("failed to cmd.Run: %s: %w", t.FilePathBin, err)
+		}
+	}
+
+	exitCode := cmd.ProcessState.ExitCode()
+	if exitCode < 0 {
+		return nil, fmt.Errorf("real command is terminated: %s", cmd.ProcessState)
+	}
+
+	return &Behavior{
+		Type: BehaviorTypeStdoutStderrExitCode,
+		ArgsMatcher: &ArgsMatcher{
+			Type: ArgsMatcherTypeExact,
+			Args: inv.Args,
+		},
+		BehaviorStdoutStderrExitCode: &BehaviorStdoutStderrExitCode{
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			ExitCode: uint8(exitCode),
+		},
+	}, nil
+}
+
+// ReadBehaviorsFile reads behaviors from a JSON file such as recordings or fixtures.
+// It returns empty behaviors if the file does not exist.
+func ReadBehaviorsFile(filePath string) (Behaviors, error) {
+	behaviors := Behaviors{}
+	b, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return behaviors, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read behaviors file: %s: %w", filePath, err)
+	}
+
+	if err := json.Unmarshal(b, &behaviors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal behaviors file: %s: %w", filePath, err)
+	}
+
+	return behaviors, nil
+}
+
+// WriteBehaviorsFile writes behaviors to a JSON file.
+func WriteBehaviorsFile(filePath string, behaviors Behaviors) error {
+	b, err := json.MarshalIndent(behaviors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to json.MarshalIndent: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, b, 0644); err != nil {
+		return fmt.Errorf("failed to os.WriteFile: %w", err)
+	}
+
+	return nil
+}
+
+// AppendRecording appends a recorded behavior to the recordings file.
+func AppendRecording(filePath string, behavior *Behavior) error {
+	recordings, err := ReadBehaviorsFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	return WriteBehaviorsFile(filePath, append(recordings, *behavior))
+}
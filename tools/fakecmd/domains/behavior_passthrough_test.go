@@ -0,0 +1,130 @@
+package domains_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/libs/e2ehelpers"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestBehaviorPassthrough_Run(t *testing.T) {
+	t.Parallel()
+
+	stdout, stderr := bytes.NewBufferString(""), bytes.NewBufferString("")
+	b := domains.BehaviorPassthrough{FilePathBin: "/bin/sh"}
+	recorded, err := b.Run(&domains.Invocation{
+		Args:   []string{"-c", "cat; echo $FOO >&2; exit 3"},
+		Env:    []string{"FOO=foo"},
+		Cwd:    t.TempDir(),
+		Stdin:  []byte("this is stdin"),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "this is stdin", stdout.String())
+	assert.Equal(t, "foo\n", stderr.String())
+	assert.Equal(t, &domains.Behavior{
+		Type: domains.BehaviorTypeStdoutStderrExitCode,
+		ArgsMatcher: &domains.ArgsMatcher{
+			Type: domains.ArgsMatcherTypeExact,
+			Args: []string{"-c", "cat; echo $FOO >&2; exit 3"},
+		},
+		BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+			Stdout:   "this is stdin",
+			Stderr:   "foo\n",
+			ExitCode: 3,
+		},
+	}, recorded)
+
+	b = domains.BehaviorPassthrough{FilePathBin: "/aaa/bbb/ccc"}
+	_, err = b.Run(&domains.Invocation{Stdout: stdout, Stderr: stderr})
+	require.EqualError(t, err, "failed to cmd.Run: /aaa/bbb/ccc: fork/exec /aaa/bbb/ccc: no such file or directory")
+}
+
+func TestAppendRecording(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "recordings.json")
+
+	require.NoError(t, domains.AppendRecording(filePath, &domains.Behavior{Type: domains.BehaviorTypeStdoutStderrExitCode}))
+	require.NoError(t, domains.AppendRecording(filePath, &domains.Behavior{Type: domains.BehaviorTypeScript}))
+
+	recordings, err := domains.ReadBehaviorsFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, domains.Behaviors{
+		{Type: domains.BehaviorTypeStdoutStderrExitCode},
+		{Type: domains.BehaviorTypeScript},
+	}, recordings)
+}
+
+func TestFaker_WithRecordingsFixtureDir(t *testing.T) {
+	t.Parallel()
+
+	filePathFakeCMD := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(filePathFakeCMD, []byte{})
+	dirPathFixture := t.TempDir()
+
+	faker := domains.New(filePathFakeCMD, domains.WithRecordingsFixtureDir(dirPathFixture))
+	t.Cleanup(func() {
+		os.RemoveAll(filePathFakeCMD) //nolint:errcheck
+		faker.Cleanup()               //nolint:errcheck
+	})
+
+	recorded := domains.Behavior{
+		Type: domains.BehaviorTypeStdoutStderrExitCode,
+		BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+			Stdout: "recorded",
+		},
+	}
+
+	t.Run("record", func(t *testing.T) {
+		fcmd := faker.AddInTest(t, domains.Behaviors{})
+		require.NoError(t, domains.AppendRecording(fcmd.DirPath().FilePathRecordings(), &recorded))
+
+		// no recordings, no fixture
+		faker.AddInTest(t, domains.Behaviors{})
+	})
+
+	fixture, err := domains.ReadBehaviorsFile(filepath.Join(dirPathFixture, "TestFaker_WithRecordingsFixtureDir_record_0.json"))
+	require.NoError(t, err)
+	assert.Equal(t, domains.Behaviors{recorded}, fixture)
+	assert.NoFileExists(t, filepath.Join(dirPathFixture, "TestFaker_WithRecordingsFixtureDir_record_1.json"))
+}
+
+func TestFaker_WithRecordingsFixtureDir_DeferCleanup(t *testing.T) {
+	t.Parallel()
+
+	filePathFakeCMD := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(filePathFakeCMD, []byte{})
+	dirPathFixture := t.TempDir()
+	t.Cleanup(func() {
+		os.RemoveAll(filePathFakeCMD) //nolint:errcheck
+	})
+
+	recorded := domains.Behavior{
+		Type: domains.BehaviorTypeStdoutStderrExitCode,
+		BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+			Stdout: "recorded",
+		},
+	}
+
+	// Cleanup deferred in the test runs before the test finishes, so the fixture is saved by it.
+	func() {
+		faker := domains.New(filePathFakeCMD, domains.WithRecordingsFixtureDir(dirPathFixture))
+		defer faker.Cleanup() //nolint:errcheck
+
+		fcmd := faker.AddInTest(t, domains.Behaviors{})
+		require.NoError(t, domains.AppendRecording(fcmd.DirPath().FilePathRecordings(), &recorded))
+	}()
+
+	fixture, err := domains.ReadBehaviorsFile(filepath.Join(dirPathFixture, "TestFaker_WithRecordingsFixtureDir_DeferCleanup_0.json"))
+	require.NoError(t, err)
+	assert.Equal(t, domains.Behaviors{recorded}, fixture)
+}
@@ -0,0 +1,102 @@
+package domains
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type ScriptStepType int
+
+const (
+	// ScriptStepTypeStdout writes Content to stdout.
+	ScriptStepTypeStdout ScriptStepType = iota + 1
+	// ScriptStepTypeStderr writes Content to stderr.
+	ScriptStepTypeStderr
+	// ScriptStepTypeCopyStdin copies stdin to stdout.
+	ScriptStepTypeCopyStdin
+	// ScriptStepTypeSleep sleeps for Duration.
+	ScriptStepTypeSleep
+	// ScriptStepTypeWriteFile creates or truncates the file at Path and writes Content.
+	ScriptStepTypeWriteFile
+	// ScriptStepTypeAppendFile appends Content to the file at Path.
+	ScriptStepTypeAppendFile
+	// ScriptStepTypeRemoveFile removes the file at Path.
+	ScriptStepTypeRemoveFile
+	// ScriptStepTypeExit exits with ExitCode. Subsequent steps are not run.
+	ScriptStepTypeExit
+)
+
+type ScriptStep struct {
+	Type    ScriptStepType
+	Content string
+	// Path is a file path. A relative path is resolved from the working directory of the invocation.
+	Path     string
+	Duration time.Duration
+	ExitCode uint8
+}
+
+// BehaviorScript runs Steps in order. It exits with 0 if no [ScriptStepTypeExit] step is run.
+type BehaviorScript struct {
+	Steps []ScriptStep
+}
+
+func (t *BehaviorScript) Run(inv *Invocation) (uint8, error) {
+	for i, step := range t.Steps {
+		exited, err := step.run(inv)
+		if err != nil {
+			return 0, fmt.Errorf("steps[%d]: %w", i, err)
+		}
+		if exited {
+			return step.ExitCode, nil
+		}
+	}
+	return 0, nil
+}
+
+func (t *ScriptStep) run(inv *Invocation) (bool, error) {
+	switch t.Type {
+	case ScriptStepTypeStdout:
+		fmt.Fprint(inv.Stdout, t.Content) //nolint:errcheck
+	case ScriptStepTypeStderr:
+		fmt.Fprint(inv.Stderr, t.Content) //nolint:errcheck
+	case ScriptStepTypeCopyStdin:
+		inv.Stdout.Write(inv.Stdin) //nolint:errcheck
+	case ScriptStepTypeSleep:
+		time.Sleep(t.Duration)
+	case ScriptStepTypeWriteFile:
+		filePath := t.resolvePath(inv.Cwd)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return false, fmt.Errorf("failed to os.MkdirAll: %w", err)
+		}
+		if err := os.WriteFile(filePath, []byte(t.Content), 0644); err != nil {
+			return false, fmt.Errorf("failed to os.WriteFile: %w", err)
+		}
+	case ScriptStepTypeAppendFile:
+		f, err := os.OpenFile(t.resolvePath(inv.Cwd), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return false, fmt.Errorf("failed to os.OpenFile: %w", err)
+		}
+		defer f.Close() //nolint:errcheck
+		if _, err := f.WriteString(t.Content); err != nil {
+			return false, fmt.Errorf("failed to WriteString: %w", err)
+		}
+	case ScriptStepTypeRemoveFile:
+		if err := os.Remove(t.resolvePath(inv.Cwd)); err != nil {
+			return false, fmt.Errorf("failed to os.Remove: %w", err)
+		}
+	case ScriptStepTypeExit:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown script step type: %d", t.Type)
+	}
+	return false, nil
+}
+
+func (t *ScriptStep) resolvePath(cwd string) string {
+	if filepath.IsAbs(t.Path) {
+		return t.Path
+	}
+	return filepath.Join(cwd, t.Path)
+}
@@ -0,0 +1,118 @@
+package domains_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestBehaviorScript_Run(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc         string
+		script       domains.BehaviorScript
+		setup        func(t *testing.T, cwd string)
+		wantExitCode uint8
+		wantStdout   string
+		wantStderr   string
+		errMsg       string
+		assertFunc   func(t *testing.T, cwd string)
+	}{
+		{
+			desc: "ok - write stdout/stderr and copy stdin",
+			script: domains.BehaviorScript{
+				Steps: []domains.ScriptStep{
+					{Type: domains.ScriptStepTypeStdout, Content: "out1\n"},
+					{Type: domains.ScriptStepTypeStderr, Content: "err1\n"},
+					{Type: domains.ScriptStepTypeCopyStdin},
+					{Type: domains.ScriptStepTypeSleep, Duration: time.Millisecond},
+				},
+			},
+			wantStdout: "out1\nthis is stdin",
+			wantStderr: "err1\n",
+		},
+		{
+			desc: "ok - exit stops subsequent steps",
+			script: domains.BehaviorScript{
+				Steps: []domains.ScriptStep{
+					{Type: domains.ScriptStepTypeStdout, Content: "out1"},
+					{Type: domains.ScriptStepTypeExit, ExitCode: 2},
+					{Type: domains.ScriptStepTypeStdout, Content: "out2"},
+				},
+			},
+			wantExitCode: 2,
+			wantStdout:   "out1",
+		},
+		{
+			desc: "ok - write, append and remove files",
+			script: domains.BehaviorScript{
+				Steps: []domains.ScriptStep{
+					{Type: domains.ScriptStepTypeWriteFile, Path: "dir/terraform.tfstate", Content: "a"},
+					{Type: domains.ScriptStepTypeAppendFile, Path: "dir/terraform.tfstate", Content: "b"},
+					{Type: domains.ScriptStepTypeRemoveFile, Path: "removed"},
+				},
+			},
+			setup: func(t *testing.T, cwd string) {
+				require.NoError(t, os.WriteFile(filepath.Join(cwd, "removed"), []byte{}, 0644))
+			},
+			assertFunc: func(t *testing.T, cwd string) {
+				b, err := os.ReadFile(filepath.Join(cwd, "dir/terraform.tfstate"))
+				require.NoError(t, err)
+				assert.Equal(t, "ab", string(b))
+				assert.NoFileExists(t, filepath.Join(cwd, "removed"))
+			},
+		},
+		{
+			desc: "ng - remove file which does not exist",
+			script: domains.BehaviorScript{
+				Steps: []domains.ScriptStep{
+					{Type: domains.ScriptStepTypeRemoveFile, Path: "/aaa/bbb/ccc"},
+				},
+			},
+			errMsg: "steps[0]: failed to os.Remove: remove /aaa/bbb/ccc: no such file or directory",
+		},
+		{
+			desc: "ng - unknown step type",
+			script: domains.BehaviorScript{
+				Steps: []domains.ScriptStep{{}},
+			},
+			errMsg: "steps[0]: unknown script step type: 0",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cwd := t.TempDir()
+			if tC.setup != nil {
+				tC.setup(t, cwd)
+			}
+
+			stdout, stderr := bytes.NewBufferString(""), bytes.NewBufferString("")
+			exitCode, err := tC.script.Run(&domains.Invocation{
+				Cwd:    cwd,
+				Stdin:  []byte("this is stdin"),
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+			if tC.errMsg != "" {
+				require.EqualError(t, err, tC.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.wantExitCode, exitCode)
+			assert.Equal(t, tC.wantStdout, stdout.String())
+			assert.Equal(t, tC.wantStderr, stderr.String())
+			if tC.assertFunc != nil {
+				tC.assertFunc(t, cwd)
+			}
+		})
+	}
+}
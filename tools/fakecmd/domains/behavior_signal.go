@@ -0,0 +1,110 @@
+package domains
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type SignalMode int
+
+const (
+	// SignalModeWaitForSignal hangs until SIGINT or SIGTERM arrives and then exits with ExitCode.
+	SignalModeWaitForSignal SignalMode = iota + 1
+	// SignalModeIgnoreSIGTERM ignores SIGTERM for Duration and then exits with ExitCode.
+	SignalModeIgnoreSIGTERM
+	// SignalModeKillSelf kills itself with Signal.
+	SignalModeKillSelf
+)
+
+type BehaviorSignal struct {
+	Mode     SignalMode
+	Duration time.Duration
+	// Signal is a signal name used by SignalModeKillSelf.
+	// It is one of SIGHUP, SIGINT, SIGKILL and SIGTERM, which terminate a Go process.
+	Signal   string
+	ExitCode uint8
+}
+
+// signalsByName are the signals which SignalModeKillSelf can kill itself with.
+// Go handles the other signals by itself, e.g. it ignores SIGUSR1 and exits with 2 dumping stacks on SIGQUIT,
+// so that the process does not die by them even after signal.Reset.
+var signalsByName = map[string]syscall.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+func signalName(sig os.Signal) string {
+	for name, s := range signalsByName {
+		if s == sig {
+			return name
+		}
+	}
+	return sig.String()
+}
+
+// SignalListener relays signals handled by a [BehaviorSignal] from [BehaviorSignal.Listen] to [SignalListener.Wait].
+type SignalListener struct {
+	behavior *BehaviorSignal
+	ch       chan os.Signal
+	// kill is the signal sent by SignalModeKillSelf.
+	kill syscall.Signal
+}
+
+// Listen starts to receive signals handled by the behavior so that signals arriving before Wait are not lost.
+// Call [SignalListener.Stop] after Wait.
+func (t *BehaviorSignal) Listen() (*SignalListener, error) {
+	l := SignalListener{behavior: t, ch: make(chan os.Signal, 1)}
+	switch t.Mode {
+	case SignalModeWaitForSignal:
+		signal.Notify(l.ch, syscall.SIGINT, syscall.SIGTERM)
+	case SignalModeIgnoreSIGTERM:
+		signal.Notify(l.ch, syscall.SIGTERM)
+	case SignalModeKillSelf:
+		sig, ok := signalsByName[t.Signal]
+		if !ok {
+			return nil, fmt.Errorf("unknown signal: %s", t.Signal)
+		}
+		l.kill = sig
+	default:
+		return nil, fmt.Errorf("unknown signal mode: %d", t.Mode)
+	}
+	return &l, nil
+}
+
+func (l *SignalListener) Stop() {
+	signal.Stop(l.ch)
+}
+
+// Wait simulates the behavior. received is called with the signal name every time a signal arrives.
+func (l *SignalListener) Wait(received func(sig string)) (uint8, error) {
+	switch l.behavior.Mode {
+	case SignalModeWaitForSignal:
+		received(signalName(<-l.ch))
+		return l.behavior.ExitCode, nil
+	case SignalModeIgnoreSIGTERM:
+		timer := time.NewTimer(l.behavior.Duration)
+		defer timer.Stop()
+		for {
+			select {
+			case sig := <-l.ch:
+				received(signalName(sig))
+			case <-timer.C:
+				return l.behavior.ExitCode, nil
+			}
+		}
+	case SignalModeKillSelf:
+		signal.Reset(l.kill)
+		if err := syscall.Kill(os.Getpid(), l.kill); err != nil {
+			return 0, fmt.Errorf("failed to syscall.Kill: %w", err)
+		}
+		// the process is expected to be terminated by the signal
+		time.Sleep(time.Second)
+		return 0, fmt.Errorf("process is still alive after %s", l.behavior.Signal)
+	}
+	return 0, fmt.Errorf("unknown signal mode: %d", l.behavior.Mode)
+}
@@ -0,0 +1,89 @@
+package domains_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+// Tests in this file send signals to the test process itself, so they must not run in parallel.
+
+func TestBehaviorSignal_ListenWait(t *testing.T) {
+	sendSIGTERM := func() {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	}
+
+	testCases := []struct {
+		desc         string
+		behavior     domains.BehaviorSignal
+		wantExitCode uint8
+		wantSignals  []string
+		errMsg       string
+	}{
+		{
+			desc: "ok - wait for signal",
+			behavior: domains.BehaviorSignal{
+				Mode:     domains.SignalModeWaitForSignal,
+				ExitCode: 143,
+			},
+			wantExitCode: 143,
+			wantSignals:  []string{"SIGTERM"},
+		},
+		{
+			desc: "ok - ignore SIGTERM",
+			behavior: domains.BehaviorSignal{
+				Mode:     domains.SignalModeIgnoreSIGTERM,
+				Duration: 200 * time.Millisecond,
+				ExitCode: 1,
+			},
+			wantExitCode: 1,
+			wantSignals:  []string{"SIGTERM"},
+		},
+		{
+			desc: "ng - signal which does not terminate a Go process",
+			behavior: domains.BehaviorSignal{
+				Mode:   domains.SignalModeKillSelf,
+				Signal: "SIGUSR1",
+			},
+			errMsg: "unknown signal: SIGUSR1",
+		},
+		{
+			desc: "ng - unknown signal",
+			behavior: domains.BehaviorSignal{
+				Mode:   domains.SignalModeKillSelf,
+				Signal: "SIGFOO",
+			},
+			errMsg: "unknown signal: SIGFOO",
+		},
+		{
+			desc:     "ng - unknown mode",
+			behavior: domains.BehaviorSignal{},
+			errMsg:   "unknown signal mode: 0",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			l, err := tC.behavior.Listen()
+			if tC.errMsg != "" {
+				require.EqualError(t, err, tC.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			defer l.Stop()
+
+			sendSIGTERM()
+			signals := []string{}
+			exitCode, err := l.Wait(func(sig string) {
+				signals = append(signals, sig)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tC.wantExitCode, exitCode)
+			assert.Equal(t, tC.wantSignals, signals)
+		})
+	}
+}
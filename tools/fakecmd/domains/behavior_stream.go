@@ -0,0 +1,48 @@
+package domains
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type StreamTarget int
+
+const (
+	StreamTargetStdout StreamTarget = iota + 1
+	StreamTargetStderr
+)
+
+// StreamChunk is written to Target after waiting for Delay.
+type StreamChunk struct {
+	Target  StreamTarget
+	Content string
+	Delay   time.Duration
+}
+
+// BehaviorStream writes Chunks in order and exits with ExitCode.
+// Each chunk is written by a single write call, so that the order of stdout and stderr is preserved.
+type BehaviorStream struct {
+	Chunks   []StreamChunk
+	ExitCode uint8
+}
+
+func (t *BehaviorStream) Run(inv *Invocation) (uint8, error) {
+	for i, chunk := range t.Chunks {
+		var w io.Writer
+		switch chunk.Target {
+		case StreamTargetStdout:
+			w = inv.Stdout
+		case StreamTargetStderr:
+			w = inv.Stderr
+		default:
+			return 0, fmt.Errorf("chunks[%d]: unknown stream target: %d", i, chunk.Target)
+		}
+
+		time.Sleep(chunk.Delay)
+		if _, err := io.WriteString(w, chunk.Content); err != nil {
+			return 0, fmt.Errorf("chunks[%d]: failed to write: %w", i, err)
+		}
+	}
+	return t.ExitCode, nil
+}
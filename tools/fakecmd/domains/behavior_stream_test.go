@@ -0,0 +1,59 @@
+package domains_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+}
+
+func (t *prefixWriter) Write(p []byte) (int, error) {
+	if _, err := t.w.Write(append([]byte(t.prefix), p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func TestBehaviorStream_Run(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok - chunks are written in order", func(t *testing.T) {
+		t.Parallel()
+
+		combined := bytes.NewBufferString("")
+		b := domains.BehaviorStream{
+			Chunks: []domains.StreamChunk{
+				{Target: domains.StreamTargetStdout, Content: "line1\n"},
+				{Target: domains.StreamTargetStderr, Content: "line2\n", Delay: time.Millisecond},
+				{Target: domains.StreamTargetStdout, Content: "line3\n"},
+			},
+			ExitCode: 2,
+		}
+		exitCode, err := b.Run(&domains.Invocation{
+			Stdout: &prefixWriter{prefix: "[out] ", w: combined},
+			Stderr: &prefixWriter{prefix: "[err] ", w: combined},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint8(2), exitCode)
+		assert.Equal(t, "[out] line1\n[err] line2\n[out] line3\n", combined.String())
+	})
+
+	t.Run("ng - unknown target", func(t *testing.T) {
+		t.Parallel()
+
+		b := domains.BehaviorStream{
+			Chunks: []domains.StreamChunk{{}},
+		}
+		_, err := b.Run(&domains.Invocation{})
+		require.EqualError(t, err, "chunks[0]: unknown stream target: 0")
+	})
+}
@@ -0,0 +1,158 @@
+package domains_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestBehaviorStdoutStderrExitCode_Run(t *testing.T) {
+	t.Parallel()
+
+	inv := domains.Invocation{
+		Args:  []string{"-chdir=/x", "plan"},
+		Env:   []string{"FOO=foo", "BAR=a=b"},
+		Cwd:   "/cwd",
+		Stdin: []byte("this is stdin"),
+		Index: 2,
+	}
+
+	testCases := []struct {
+		desc         string
+		behavior     domains.BehaviorStdoutStderrExitCode
+		wantExitCode uint8
+		wantStdout   string
+		wantStderr   string
+		errMsg       string
+	}{
+		{
+			desc: "ok - not template",
+			behavior: domains.BehaviorStdoutStderrExitCode{
+				Stdout:   "{{.Cwd}}",
+				Stderr:   "err",
+				ExitCode: 1,
+			},
+			wantExitCode: 1,
+			wantStdout:   "{{.Cwd}}",
+			wantStderr:   "err",
+		},
+		{
+			desc: "ok - template",
+			behavior: domains.BehaviorStdoutStderrExitCode{
+				Stdout:   `{{index .Args 0}} {{index .Args 1}} {{.Env.FOO}} {{.Env.BAR}} {{.Cwd}} {{.Index}}`,
+				Stderr:   `{{.Stdin}}`,
+				Template: true,
+			},
+			wantStdout: "-chdir=/x plan foo a=b /cwd 2",
+			wantStderr: "this is stdin",
+		},
+		{
+			desc: "ng - invalid template",
+			behavior: domains.BehaviorStdoutStderrExitCode{
+				Stdout:   `{{.Args`,
+				Template: true,
+			},
+			errMsg: `failed to parse template: template: Stdout:1: unclosed action`,
+		},
+		{
+			desc: "ng - missing key",
+			behavior: domains.BehaviorStdoutStderrExitCode{
+				Stderr:   `{{.Env.BAZ}}`,
+				Template: true,
+			},
+			errMsg: `failed to execute template: template: Stderr:1:6: executing "Stderr" at <.Env.BAZ>: map has no entry for key "BAZ"`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stdout, stderr := bytes.NewBufferString(""), bytes.NewBufferString("")
+			inv := inv
+			inv.Stdout = stdout
+			inv.Stderr = stderr
+
+			exitCode, err := tC.behavior.Run(&inv)
+			if tC.errMsg != "" {
+				require.EqualError(t, err, tC.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.wantExitCode, exitCode)
+			assert.Equal(t, tC.wantStdout, stdout.String())
+			assert.Equal(t, tC.wantStderr, stderr.String())
+		})
+	}
+}
+
+func TestBehavior_UsesStdin(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		behavior domains.Behavior
+		want     bool
+	}{
+		{
+			desc: "stdout without template",
+			behavior: domains.Behavior{
+				Type:                         domains.BehaviorTypeStdoutStderrExitCode,
+				BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{Stdout: "{{.Stdin}}"},
+			},
+			want: false,
+		},
+		{
+			desc: "template refers stdin",
+			behavior: domains.Behavior{
+				Type:                         domains.BehaviorTypeStdoutStderrExitCode,
+				BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{Stderr: "{{.Stdin}}", Template: true},
+			},
+			want: true,
+		},
+		{
+			desc: "template does not refer stdin",
+			behavior: domains.Behavior{
+				Type:                         domains.BehaviorTypeStdoutStderrExitCode,
+				BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{Stdout: "{{.Cwd}}", Template: true},
+			},
+			want: false,
+		},
+		{
+			desc: "stdin matcher",
+			behavior: domains.Behavior{
+				Type:         domains.BehaviorTypeStdoutStderrExitCode,
+				StdinMatcher: &domains.StdinMatcher{Type: domains.StdinMatcherTypeExact},
+			},
+			want: true,
+		},
+		{
+			desc: "script copies stdin",
+			behavior: domains.Behavior{
+				Type: domains.BehaviorTypeScript,
+				BehaviorScript: &domains.BehaviorScript{Steps: []domains.ScriptStep{
+					{Type: domains.ScriptStepTypeStdout},
+					{Type: domains.ScriptStepTypeCopyStdin},
+				}},
+			},
+			want: true,
+		},
+		{
+			desc:     "passthrough",
+			behavior: domains.Behavior{Type: domains.BehaviorTypePassthrough},
+			want:     true,
+		},
+		{
+			desc:     "signal",
+			behavior: domains.Behavior{Type: domains.BehaviorTypeSignal},
+			want:     false,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.want, tC.behavior.UsesStdin())
+		})
+	}
+}
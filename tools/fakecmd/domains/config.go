@@ -0,0 +1,102 @@
+package domains
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config is a setting of a fake command which is not tied to each behavior.
+type Config struct {
+	// EnvAllowlist is names of environment variables recorded in [ExecutedHistory].
+	EnvAllowlist []string
+	// LockTimeout is how long an invocation waits for other invocations to update the state.
+	// Zero means [DefaultLockTimeout].
+	LockTimeout time.Duration
+	// CaptureStdin makes every invocation read stdin to EOF and record it in [ExecutedHistory].
+	// Otherwise stdin is read only when the used behavior needs it. See [Behavior.UsesStdin].
+	CaptureStdin bool
+}
+
+const DefaultLockTimeout = 30 * time.Second
+
+func (t *Config) LockTimeoutOrDefault() time.Duration {
+	if t.LockTimeout <= 0 {
+		return DefaultLockTimeout
+	}
+	return t.LockTimeout
+}
+
+// FilterEnv returns environment variables whose names are in EnvAllowlist given "KEY=VALUE" formatted environ.
+func (t *Config) FilterEnv(environ []string) map[string]string {
+	allowed := map[string]bool{}
+	for _, k := range t.EnvAllowlist {
+		allowed[k] = true
+	}
+
+	env := map[string]string{}
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		if allowed[k] {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// ReadConfig reads config file. It returns empty config if the file does not exist.
+func ReadConfig(filePath string) (*Config, error) {
+	config := Config{}
+	b, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return &config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %s: %w", filePath, err)
+	}
+
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config file: %s: %w", filePath, err)
+	}
+
+	return &config, nil
+}
+
+type FakeCommandOption func(t *FakeCommand)
+
+// WithEnvAllowlist sets names of environment variables recorded in [ExecutedHistory].
+func WithEnvAllowlist(keys ...string) FakeCommandOption {
+	return func(t *FakeCommand) {
+		t.config.EnvAllowlist = append(t.config.EnvAllowlist, keys...)
+	}
+}
+
+// WithLockTimeout sets how long an invocation waits for other invocations to update the state.
+func WithLockTimeout(d time.Duration) FakeCommandOption {
+	return func(t *FakeCommand) {
+		t.config.LockTimeout = d
+	}
+}
+
+// WithCaptureStdin makes every invocation record stdin. See [Config.CaptureStdin].
+func WithCaptureStdin() FakeCommandOption {
+	return func(t *FakeCommand) {
+		t.config.CaptureStdin = true
+	}
+}
+
+// WithWhenExhausted sets what the fake command does when no unused behavior matches.
+func WithWhenExhausted(w WhenExhausted) FakeCommandOption {
+	return func(t *FakeCommand) {
+		t.spec.WhenExhausted = w
+	}
+}
+
+// WithDefaultBehavior makes the fake command use b when no unused behavior matches.
+func WithDefaultBehavior(b Behavior) FakeCommandOption {
+	return func(t *FakeCommand) {
+		t.spec.WhenExhausted = WhenExhaustedDefault
+		t.spec.DefaultBehavior = &b
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
@@ -13,57 +16,280 @@ import (
 type Faker struct {
 	filePathFakeCMD string
 
+	mu       sync.Mutex
 	dirPaths DirPathFakeCommands
+
+	// dirPathRecordingsFixture is a directory where recordings of each fake command are saved as behaviors fixture.
+	dirPathRecordingsFixture string
+	numFakesPerTest          map[string]int
+	recordingsFixtures       []*recordingsFixture
+
+	// dirPathBin is a directory where fake commands are installed under their real names.
+	dirPathBin string
+	// dirPathBinsPerTest are bin directories of [Faker.AddNamedInTest] keyed by test name,
+	// so that subtests sharing the faker can install the same name.
+	dirPathBinsPerTest map[string]string
+
+	// inProcess is true if fake commands are served by the running test binary. See [NewInProcess].
+	inProcess bool
+}
+
+type FakerOption func(f *Faker)
+
+// WithRecordingsFixtureDir makes [Faker.AddInTest] save recordings of [BehaviorTypePassthrough] as a behaviors fixture
+// into dirPath when the test finishes or [Faker.Cleanup] is called, whichever comes first.
+// The fixture is named after the test and can be replayed by [ReadBehaviorsFile].
+func WithRecordingsFixtureDir(dirPath string) FakerOption {
+	return func(f *Faker) {
+		f.dirPathRecordingsFixture = dirPath
+	}
 }
 
 func (t *Faker) Add(
 	dirPathCommand DirPathFakeCommand,
 	behaviors Behaviors,
+	opts ...FakeCommandOption,
 ) *FakeCommand {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.dirPaths = append(t.dirPaths, dirPathCommand)
 
-	fcmd := FakeCommand{
-		filePathFakeCMD: t.filePathFakeCMD,
-		dirPath:         dirPathCommand,
-		behaviors:       behaviors,
+	if t.inProcess {
+		opts = append([]FakeCommandOption{WithInProcess()}, opts...)
 	}
 
-	return &fcmd
+	return NewFakeCommand(
+		t.filePathFakeCMD,
+		dirPathCommand,
+		behaviors,
+		opts...,
+	)
 }
 
 func (t *Faker) Cleanup() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	errs := []error{}
+	// Fixtures are saved before the fake commands are removed, because Cleanup deferred in a test runs before tt.Cleanup.
+	for _, f := range t.recordingsFixtures {
+		errs = append(errs, f.save())
+	}
 	for _, d := range t.dirPaths {
 		errs = append(errs, os.RemoveAll(d.String()))
 	}
+	if t.dirPathBin != "" {
+		errs = append(errs, os.RemoveAll(t.dirPathBin))
+	}
+	for _, d := range t.dirPathBinsPerTest {
+		errs = append(errs, os.RemoveAll(d))
+	}
 	return errors.Join(errs...)
 }
 
-func (t *Faker) AddInTest(tt *testing.T, behaviors Behaviors) *FakeCommand {
+// Install installs the fake command into the bin directory of the faker as name.
+// Commands looked up via PATH can be faked by putting [Faker.PathEnv] into environment variables.
+// The bin directory is shared by all callers, so name can be installed only once. Use [Faker.AddNamedInTest] in tests.
+func (t *Faker) Install(name string, fcmd *FakeCommand) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.dirPathBin == "" {
+		dirPathBin, err := newDirPathBin()
+		if err != nil {
+			return err
+		}
+		t.dirPathBin = dirPathBin
+	}
+
+	return install(t.dirPathBin, name, fcmd)
+}
+
+func newDirPathBin() (string, error) {
+	dirPathBin := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	if err := os.MkdirAll(dirPathBin, 0755); err != nil {
+		return "", fmt.Errorf("failed to os.MkdirAll: %w", err)
+	}
+	return dirPathBin, nil
+}
+
+func install(dirPathBin string, name string, fcmd *FakeCommand) error {
+	if err := os.Symlink(fcmd.DirPath().FilePathCommand(), filepath.Join(dirPathBin, name)); err != nil {
+		return fmt.Errorf("failed to os.Symlink: %w", err)
+	}
+	return nil
+}
+
+// DirPathBin returns the bin directory where fake commands are installed by [Faker.Install].
+// It returns empty string if no fake commands are installed.
+func (t *Faker) DirPathBin() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.dirPathBin
+}
+
+// PathEnv returns "PATH=..." environment variable which puts the bin directory first.
+// It returns PATH as it is if no fake commands are installed,
+// because an empty entry of PATH means the current directory.
+func (t *Faker) PathEnv() string {
+	return pathEnv(t.DirPathBin())
+}
+
+func pathEnv(dirPathBin string) string {
+	if dirPathBin == "" {
+		return "PATH=" + os.Getenv("PATH")
+	}
+	return fmt.Sprintf("PATH=%s%c%s", dirPathBin, os.PathListSeparator, os.Getenv("PATH"))
+}
+
+// AddNamedInTest adds a fake command and installs it as name into the bin directory of tt.
+// Each test has its own bin directory, which is removed when the test finishes,
+// so that subtests sharing the faker, even in parallel, can install the same name.
+// Use [Faker.PathEnvInTest] to look it up via PATH.
+func (t *Faker) AddNamedInTest(tt *testing.T, name string, behaviors Behaviors, opts ...FakeCommandOption) *FakeCommand {
+	fcmd := t.AddInTest(tt, behaviors, opts...)
+	require.NoError(tt, t.installInTest(tt, name, fcmd))
+	return fcmd
+}
+
+func (t *Faker) installInTest(tt *testing.T, name string, fcmd *FakeCommand) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dirPathBin, ok := t.dirPathBinsPerTest[tt.Name()]
+	if !ok {
+		var err error
+		dirPathBin, err = newDirPathBin()
+		if err != nil {
+			return err
+		}
+		t.dirPathBinsPerTest[tt.Name()] = dirPathBin
+		tt.Cleanup(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			delete(t.dirPathBinsPerTest, tt.Name())
+			os.RemoveAll(dirPathBin) //nolint:errcheck
+		})
+	}
+
+	return install(dirPathBin, name, fcmd)
+}
+
+// DirPathBinInTest returns the bin directory of tt where fake commands are installed by [Faker.AddNamedInTest].
+// It returns empty string if no fake commands are installed in tt.
+func (t *Faker) DirPathBinInTest(tt *testing.T) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.dirPathBinsPerTest[tt.Name()]
+}
+
+// PathEnvInTest is like [Faker.PathEnv] but puts the bin directory of tt first. See [Faker.AddNamedInTest].
+func (t *Faker) PathEnvInTest(tt *testing.T) string {
+	return pathEnv(t.DirPathBinInTest(tt))
+}
+
+func (t *Faker) AddInTest(tt *testing.T, behaviors Behaviors, opts ...FakeCommandOption) *FakeCommand {
 	fcmd := t.Add(
 		DirPathFakeCommand(fmt.Sprintf("/tmp/%s", uuid.NewString())),
 		behaviors,
+		opts...,
 	)
 	require.NoError(tt, fcmd.Init(false))
+
+	if t.dirPathRecordingsFixture != "" {
+		f := t.addRecordingsFixture(fcmd, tt.Name())
+		tt.Cleanup(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			require.NoError(tt, f.save())
+		})
+	}
+
 	return fcmd
 }
 
+var regexpUnsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// recordingsFixture is a fixture of recordings of a fake command which is saved once. See [WithRecordingsFixtureDir].
+// It is guarded by mu of [Faker].
+type recordingsFixture struct {
+	fcmd     *FakeCommand
+	filePath string
+	saved    bool
+}
+
+func (t *Faker) addRecordingsFixture(fcmd *FakeCommand, testName string) *recordingsFixture {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := t.numFakesPerTest[testName]
+	t.numFakesPerTest[testName]++
+
+	f := recordingsFixture{
+		fcmd: fcmd,
+		filePath: filepath.Join(
+			t.dirPathRecordingsFixture,
+			fmt.Sprintf("%s_%d.json", regexpUnsafeFileNameChars.ReplaceAllString(testName, "_"), n),
+		),
+	}
+	t.recordingsFixtures = append(t.recordingsFixtures, &f)
+	return &f
+}
+
+func (f *recordingsFixture) save() error {
+	if f.saved {
+		return nil
+	}
+	f.saved = true
+
+	// Recordings of a removed fake command are lost, which must not be mistaken for no recordings.
+	if _, err := os.Stat(f.fcmd.DirPath().String()); err != nil {
+		return fmt.Errorf("failed to save recordings fixture: %s: %w", f.filePath, err)
+	}
+
+	recordings, err := f.fcmd.Recordings()
+	if err != nil {
+		return err
+	}
+	if len(recordings) <= 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to os.MkdirAll: %w", err)
+	}
+
+	return WriteBehaviorsFile(f.filePath, recordings)
+}
+
 func New(
 	filePathFakeCMD string,
+	opts ...FakerOption,
 ) *Faker {
-	return &Faker{
-		filePathFakeCMD: filePathFakeCMD,
-		dirPaths:        DirPathFakeCommands{},
+	f := Faker{
+		filePathFakeCMD:    filePathFakeCMD,
+		dirPaths:           DirPathFakeCommands{},
+		numFakesPerTest:    map[string]int{},
+		dirPathBinsPerTest: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&f)
 	}
+	return &f
 }
 
 const envName = "FILE_PATH_FAKECMD"
 
-func MustByEnv() *Faker {
+func MustByEnv(opts ...FakerOption) *Faker {
 	envVal := os.Getenv(envName)
 	if envVal == "" {
 		panic(fmt.Errorf("environment variable '%s' is empty", envName))
 	}
 
-	return New(envVal)
+	return New(envVal, opts...)
 }
@@ -2,6 +2,7 @@ package domains
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -25,8 +26,16 @@ func (t DirPathFakeCommand) FilePathState() string {
 	return fmt.Sprintf("%s/state.json", t)
 }
 
-func (t DirPathFakeCommand) FilePathProcessing() string {
-	return fmt.Sprintf("%s/processing", t)
+func (t DirPathFakeCommand) FilePathConfig() string {
+	return fmt.Sprintf("%s/config.json", t)
+}
+
+func (t DirPathFakeCommand) FilePathRecordings() string {
+	return fmt.Sprintf("%s/recordings.json", t)
+}
+
+func (t DirPathFakeCommand) FilePathLock() string {
+	return fmt.Sprintf("%s/lock", t)
 }
 
 type DirPathFakeCommands []DirPathFakeCommand
@@ -34,15 +43,27 @@ type DirPathFakeCommands []DirPathFakeCommand
 type FakeCommand struct {
 	filePathFakeCMD string
 	dirPath         DirPathFakeCommand
-	behaviors       Behaviors
+	spec            Spec
+	config          Config
+
+	// inProcess is true if filePathFakeCMD is a Go test binary. See [WithInProcess].
+	inProcess bool
 }
 
 func (t *FakeCommand) Init(force bool) error {
+	if err := t.spec.Validate(); err != nil {
+		return fmt.Errorf("invalid behaviors: %w", err)
+	}
+
 	if err := t.initBaseDir(force); err != nil {
 		return err
 	}
 
-	if err := t.initCopyFakeCMD(); err != nil {
+	if t.inProcess {
+		if err := t.initInProcessScript(); err != nil {
+			return err
+		}
+	} else if err := t.initCopyFakeCMD(); err != nil {
 		return err
 	}
 
@@ -50,6 +71,10 @@ func (t *FakeCommand) Init(force bool) error {
 		return err
 	}
 
+	if err := t.initConfig(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -103,18 +128,66 @@ func (t *FakeCommand) initCopyFakeCMD() error {
 }
 
 func (t *FakeCommand) initBehaviors() error {
-	b, err := json.MarshalIndent(t.behaviors, "", "  ")
+	return WriteSpecFile(t.dirPath.FilePathBehaviors(), &t.spec)
+}
+
+func (t *FakeCommand) initConfig() error {
+	b, err := json.MarshalIndent(t.config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to json.MarshalIndent: %w", err)
 	}
 
-	if err := os.WriteFile(t.dirPath.FilePathBehaviors(), b, 0644); err != nil {
+	if err := os.WriteFile(t.dirPath.FilePathConfig(), b, 0644); err != nil {
 		return fmt.Errorf("failed to os.WriteFile: %w", err)
 	}
 
 	return nil
 }
 
+// Histories returns invocations of the fake command recorded so far.
+func (t *FakeCommand) Histories() (ExecutedHistories, error) {
+	state, err := ReadState(t.dirPath.FilePathState())
+	if err != nil {
+		return nil, err
+	}
+	return state.ExecutedHistories, nil
+}
+
+// Recordings returns behaviors recorded by [BehaviorTypePassthrough] so far.
+func (t *FakeCommand) Recordings() (Behaviors, error) {
+	return ReadBehaviorsFile(t.dirPath.FilePathRecordings())
+}
+
+// Reset removes recorded invocations and recordings so that the fake command can be used from scratch.
+func (t *FakeCommand) Reset() error {
+	errs := []error{}
+	for _, filePath := range []string{t.dirPath.FilePathState(), t.dirPath.FilePathRecordings()} {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to os.Remove: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verify returns an error if some behaviors have not been used.
+func (t *FakeCommand) Verify() error {
+	histories, err := t.Histories()
+	if err != nil {
+		return err
+	}
+
+	state := State{ExecutedHistories: histories}
+	used := state.UsedBehaviorIndexes()
+	if !t.spec.Behaviors.AllUsed(used) {
+		return fmt.Errorf(
+			"some behaviors are not consumed:\n%s",
+			t.spec.Behaviors.DescribeCandidates(used),
+		)
+	}
+
+	return nil
+}
+
 func (t *FakeCommand) Cleanup() error {
 	if err := os.RemoveAll(t.dirPath.String()); err != nil {
 		return fmt.Errorf("failed to remove dir: %w", err)
@@ -130,10 +203,40 @@ func NewFakeCommand(
 	filePathFakeCMD string,
 	dirPath DirPathFakeCommand,
 	behaviors Behaviors,
+	opts ...FakeCommandOption,
 ) *FakeCommand {
-	return &FakeCommand{
+	fcmd := FakeCommand{
 		filePathFakeCMD: filePathFakeCMD,
 		dirPath:         dirPath,
-		behaviors:       behaviors,
+		spec:            Spec{Version: SpecVersion, Behaviors: behaviors},
 	}
+	for _, opt := range opts {
+		opt(&fcmd)
+	}
+
+	return &fcmd
+}
+
+// OpenFakeCommand returns a fake command which has already been initialized in dirPath.
+func OpenFakeCommand(dirPath DirPathFakeCommand) (*FakeCommand, error) {
+	if _, err := os.Stat(dirPath.FilePathBehaviors()); err != nil {
+		return nil, fmt.Errorf("fake command is not initialized: %s: %w", dirPath, err)
+	}
+
+	spec, err := ReadSpecFile(dirPath.FilePathBehaviors())
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := ReadConfig(dirPath.FilePathConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &FakeCommand{
+		filePathFakeCMD: dirPath.FilePathCommand(),
+		dirPath:         dirPath,
+		spec:            *spec,
+		config:          *config,
+	}, nil
 }
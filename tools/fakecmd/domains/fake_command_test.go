@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -17,9 +18,11 @@ func TestDirPathFakeCommand(t *testing.T) {
 
 	d := domains.DirPathFakeCommand("hoge")
 	assert.Equal(t, "hoge/cmd", d.FilePathCommand())
-	assert.Equal(t, "hoge/processing", d.FilePathProcessing())
+	assert.Equal(t, "hoge/lock", d.FilePathLock())
 	assert.Equal(t, "hoge/behaviors.json", d.FilePathBehaviors())
 	assert.Equal(t, "hoge/state.json", d.FilePathState())
+	assert.Equal(t, "hoge/config.json", d.FilePathConfig())
+	assert.Equal(t, "hoge/recordings.json", d.FilePathRecordings())
 	assert.Equal(t, "hoge", d.String())
 }
 
@@ -55,6 +58,7 @@ func TestFakeCommand_Init(t *testing.T) {
 				require.DirExists(t, dirPath.String())
 				require.FileExists(t, dirPath.FilePathBehaviors())
 				require.FileExists(t, dirPath.FilePathCommand())
+				require.FileExists(t, dirPath.FilePathConfig())
 			},
 		},
 		{
@@ -79,6 +83,14 @@ func TestFakeCommand_Init(t *testing.T) {
 			wantErr: true,
 			errMsg:  "cloned fakecmd already exists: /tmp/case003/cmd",
 		},
+		{
+			desc:                 "ng - behaviors are invalid",
+			inputFilePathFakeCMD: filePathFakeCMD,
+			inputDirPath:         domains.DirPathFakeCommand("/tmp/case004"),
+			inputBehaviors:       domains.Behaviors{{}},
+			wantErr:              true,
+			errMsg:               "invalid behaviors: Behaviors[0].Type: unknown value 0",
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -107,3 +119,207 @@ func TestFakeCommand_Init(t *testing.T) {
 		})
 	}
 }
+
+func TestFakeCommand_Histories(t *testing.T) {
+	t.Parallel()
+
+	dirPath := domains.DirPathFakeCommand(fmt.Sprintf("/tmp/%s", uuid.NewString()))
+	e2ehelpers.MustMkdir(dirPath.String())
+	t.Cleanup(func() {
+		os.RemoveAll(dirPath.String()) //nolint:errcheck
+	})
+
+	fcmd := domains.NewFakeCommand("", dirPath, domains.Behaviors{})
+
+	histories, err := fcmd.Histories()
+	require.NoError(t, err)
+	assert.Empty(t, histories)
+
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathState(), domains.State{
+		ExecutedHistories: domains.ExecutedHistories{
+			{BehaviorIndex: 1, Args: []string{"plan"}, Cwd: "/x"},
+		},
+	})
+
+	histories, err = fcmd.Histories()
+	require.NoError(t, err)
+	assert.Equal(t, domains.ExecutedHistories{
+		{BehaviorIndex: 1, Args: []string{"plan"}, Cwd: "/x"},
+	}, histories)
+
+	e2ehelpers.MustWriteFile(dirPath.FilePathState(), []byte("a"))
+
+	_, err = fcmd.Histories()
+	require.EqualError(t, err, fmt.Sprintf(
+		"failed to unmarshal state file: %s: invalid character 'a' looking for beginning of value",
+		dirPath.FilePathState(),
+	))
+}
+
+func TestConfig_FilterEnv(t *testing.T) {
+	t.Parallel()
+
+	config := domains.Config{EnvAllowlist: []string{"FOO", "BAZ"}}
+
+	assert.Equal(
+		t,
+		map[string]string{"FOO": "1", "BAZ": "a=b"},
+		config.FilterEnv([]string{"FOO=1", "BAR=2", "BAZ=a=b"}),
+	)
+}
+
+func TestOpenFakeCommand(t *testing.T) {
+	t.Parallel()
+
+	filePathFakeCMD := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(filePathFakeCMD, []byte{})
+	dirPath := domains.DirPathFakeCommand(fmt.Sprintf("/tmp/%s", uuid.NewString()))
+	t.Cleanup(func() {
+		os.RemoveAll(filePathFakeCMD)  //nolint:errcheck
+		os.RemoveAll(dirPath.String()) //nolint:errcheck
+	})
+
+	_, err := domains.OpenFakeCommand(dirPath)
+	require.EqualError(t, err, fmt.Sprintf(
+		"fake command is not initialized: %s: stat %s: no such file or directory",
+		dirPath, dirPath.FilePathBehaviors(),
+	))
+
+	behavior := domains.Behavior{
+		Type:                         domains.BehaviorTypeStdoutStderrExitCode,
+		BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{},
+	}
+	require.NoError(t, domains.NewFakeCommand(filePathFakeCMD, dirPath, domains.Behaviors{behavior, behavior}).Init(false))
+
+	fcmd, err := domains.OpenFakeCommand(dirPath)
+	require.NoError(t, err)
+	require.EqualError(t, fcmd.Verify(), "some behaviors are not consumed:\nbehaviors[0]: any\nbehaviors[1]: any")
+
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathState(), domains.State{
+		ExecutedHistories: domains.ExecutedHistories{{BehaviorIndex: 0}, {BehaviorIndex: 1}},
+	})
+	e2ehelpers.MustWriteJSONFile(dirPath.FilePathRecordings(), domains.Behaviors{})
+	require.NoError(t, fcmd.Verify())
+
+	require.NoError(t, fcmd.Reset())
+	assert.NoFileExists(t, dirPath.FilePathState())
+	assert.NoFileExists(t, dirPath.FilePathRecordings())
+	assert.FileExists(t, dirPath.FilePathBehaviors())
+	require.NoError(t, fcmd.Reset())
+}
+
+func TestFaker_Install(t *testing.T) {
+	t.Parallel()
+
+	filePathFakeCMD := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(filePathFakeCMD, []byte{})
+
+	faker := domains.New(filePathFakeCMD)
+	t.Cleanup(func() {
+		os.RemoveAll(filePathFakeCMD) //nolint:errcheck
+	})
+
+	assert.Equal(t, "", faker.DirPathBin())
+	assert.Equal(t, "PATH="+os.Getenv("PATH"), faker.PathEnv())
+
+	fcmd := faker.AddInTest(t, domains.Behaviors{})
+	require.NoError(t, faker.Install("terraform", fcmd))
+
+	dirPathBin := faker.DirPathBin()
+	require.DirExists(t, dirPathBin)
+	link, err := os.Readlink(dirPathBin + "/terraform")
+	require.NoError(t, err)
+	assert.Equal(t, fcmd.DirPath().FilePathCommand(), link)
+	assert.Equal(t, fmt.Sprintf("PATH=%s:%s", dirPathBin, os.Getenv("PATH")), faker.PathEnv())
+
+	require.EqualError(
+		t,
+		faker.Install("terraform", fcmd),
+		fmt.Sprintf("failed to os.Symlink: symlink %s %s/terraform: file exists", fcmd.DirPath().FilePathCommand(), dirPathBin),
+	)
+
+	require.NoError(t, faker.Cleanup())
+	assert.NoDirExists(t, dirPathBin)
+	assert.NoDirExists(t, fcmd.DirPath().String())
+}
+
+func TestFaker_AddNamedInTest(t *testing.T) {
+	t.Parallel()
+
+	filePathFakeCMD := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(filePathFakeCMD, []byte{})
+
+	faker := domains.New(filePathFakeCMD)
+	t.Cleanup(func() {
+		faker.Cleanup()               //nolint:errcheck
+		os.RemoveAll(filePathFakeCMD) //nolint:errcheck
+	})
+
+	// Subtests share the faker like a table of test cases and install the same name.
+	dirPathBins := make([]string, 2)
+	t.Run("subtests", func(t *testing.T) {
+		for i := range dirPathBins {
+			t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+				t.Parallel()
+
+				assert.Equal(t, "PATH="+os.Getenv("PATH"), faker.PathEnvInTest(t))
+
+				fcmd := faker.AddNamedInTest(t, "terraform", domains.Behaviors{})
+
+				dirPathBin := faker.DirPathBinInTest(t)
+				require.DirExists(t, dirPathBin)
+				link, err := os.Readlink(dirPathBin + "/terraform")
+				require.NoError(t, err)
+				assert.Equal(t, fcmd.DirPath().FilePathCommand(), link)
+				assert.Equal(t, fmt.Sprintf("PATH=%s:%s", dirPathBin, os.Getenv("PATH")), faker.PathEnvInTest(t))
+				dirPathBins[i] = dirPathBin
+			})
+		}
+	})
+
+	assert.NotEqual(t, dirPathBins[0], dirPathBins[1])
+	for _, d := range dirPathBins {
+		assert.NoDirExists(t, d)
+	}
+	assert.Equal(t, "", faker.DirPathBin())
+}
+
+func TestNewFakeCommand_Options(t *testing.T) {
+	t.Parallel()
+
+	filePathFakeCMD := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(filePathFakeCMD, []byte{})
+	dirPath := domains.DirPathFakeCommand(fmt.Sprintf("/tmp/%s", uuid.NewString()))
+	t.Cleanup(func() {
+		os.RemoveAll(filePathFakeCMD)  //nolint:errcheck
+		os.RemoveAll(dirPath.String()) //nolint:errcheck
+	})
+
+	behavior := domains.Behavior{
+		Type:                         domains.BehaviorTypeStdoutStderrExitCode,
+		BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{},
+	}
+	fcmd := domains.NewFakeCommand(
+		filePathFakeCMD,
+		dirPath,
+		domains.Behaviors{behavior},
+		domains.WithEnvAllowlist("FOO"),
+		domains.WithLockTimeout(time.Second),
+		domains.WithCaptureStdin(),
+		domains.WithDefaultBehavior(behavior),
+	)
+	require.NoError(t, fcmd.Init(false))
+
+	config, err := domains.ReadConfig(dirPath.FilePathConfig())
+	require.NoError(t, err)
+	assert.Equal(t, &domains.Config{EnvAllowlist: []string{"FOO"}, LockTimeout: time.Second, CaptureStdin: true}, config)
+
+	spec, err := domains.ReadSpecFile(dirPath.FilePathBehaviors())
+	require.NoError(t, err)
+	assert.Equal(t, &domains.Spec{
+		Version:         domains.SpecVersion,
+		Behaviors:       domains.Behaviors{behavior},
+		WhenExhausted:   domains.WhenExhaustedDefault,
+		DefaultBehavior: &behavior,
+	}, spec)
+}
@@ -0,0 +1,102 @@
+package domains
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envNameInProcessDir is set by the wrapper script of an in-process fake command
+// to the directory of the fake command.
+const envNameInProcessDir = "FAKECMD_IN_PROCESS_DIR"
+
+// inProcessGuardArg is passed to the test binary before argv of the fake command.
+// If TestMain of the test binary does not call [RunIfInProcess], the test binary fails with exit code 2
+// telling that this flag is not defined, instead of running the tests, which invoke the fake command again, recursively.
+const inProcessGuardArg = "-test.fakecmd-requires-RunIfInProcess-in-TestMain"
+
+// WithInProcess makes filePathFakeCMD of [NewFakeCommand] be a Go test binary instead of the fakecmd binary.
+// The fake command becomes a script which re-executes the test binary,
+// and [RunIfInProcess] called in TestMain of the test binary runs the fake command.
+func WithInProcess() FakeCommandOption {
+	return func(t *FakeCommand) {
+		t.inProcess = true
+	}
+}
+
+// RunIfInProcess runs the fake command and exits if the process has been started as an in-process fake command.
+// Otherwise it does nothing.
+// Call it at the beginning of TestMain of tests using [NewInProcess] or [WithInProcess].
+//
+//	func TestMain(m *testing.M) {
+//		domains.RunIfInProcess()
+//		os.Exit(m.Run())
+//	}
+func RunIfInProcess() {
+	dirPath := os.Getenv(envNameInProcessDir)
+	if dirPath == "" {
+		return
+	}
+
+	// not to be inherited by commands which the fake command runs
+	os.Unsetenv(envNameInProcessDir) //nolint:errcheck
+
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == inProcessGuardArg {
+		args = args[1:]
+	}
+
+	os.Exit(int(Run(DirPathFakeCommand(dirPath), args)))
+}
+
+func (t *FakeCommand) initInProcessScript() error {
+	if _, err := os.Stat(t.filePathFakeCMD); err != nil {
+		return fmt.Errorf("fakecmd is invalid: %s: %w", t.filePathFakeCMD, err)
+	}
+
+	dstFilePathFakeCMD := t.dirPath.FilePathCommand()
+
+	if _, err := os.Stat(dstFilePathFakeCMD); err == nil {
+		return fmt.Errorf("cloned fakecmd already exists: %s", dstFilePathFakeCMD)
+	}
+
+	script := fmt.Sprintf(
+		"#!/bin/sh\n%s=%s exec %s %s \"$@\"\n",
+		envNameInProcessDir,
+		shellQuote(t.dirPath.String()),
+		shellQuote(t.filePathFakeCMD),
+		shellQuote(inProcessGuardArg),
+	)
+	if err := os.WriteFile(dstFilePathFakeCMD, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to os.WriteFile: %w", err)
+	}
+
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// NewInProcess returns a faker whose fake commands are served by the running test binary.
+// Unlike [New] and [MustByEnv], the fakecmd binary is not needed.
+// See [RunIfInProcess].
+func NewInProcess(opts ...FakerOption) (*Faker, error) {
+	filePathTestBin, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to os.Executable: %w", err)
+	}
+
+	f := New(filePathTestBin, opts...)
+	f.inProcess = true
+	return f, nil
+}
+
+// MustInProcess is like [NewInProcess] but panics on error.
+func MustInProcess(opts ...FakerOption) *Faker {
+	f, err := NewInProcess(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
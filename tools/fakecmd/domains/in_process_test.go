@@ -0,0 +1,92 @@
+package domains_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/libs/e2ehelpers"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestMain(m *testing.M) {
+	domains.RunIfInProcess()
+	os.Exit(m.Run())
+}
+
+func TestFaker_InProcess(t *testing.T) {
+	t.Parallel()
+
+	faker := domains.MustInProcess()
+	t.Cleanup(func() {
+		faker.Cleanup() //nolint:errcheck
+	})
+
+	fcmd := faker.AddNamedInTest(t, "terraform", domains.Behaviors{
+		{
+			Type:        domains.BehaviorTypeStdoutStderrExitCode,
+			ArgsMatcher: &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: []string{"plan", "-no-color"}},
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout:   "stdin is {{.Stdin}}",
+				Stderr:   "this is stderr",
+				ExitCode: 2,
+				Template: true,
+			},
+		},
+	})
+
+	cmd := exec.Command(faker.DirPathBinInTest(t)+"/terraform", "plan", "-no-color")
+	cmd.Stdin = strings.NewReader("foo")
+	stdout := strings.Builder{}
+	stderr := strings.Builder{}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Equal(t, "stdin is foo", stdout.String())
+	assert.Equal(t, "this is stderr", stderr.String())
+
+	fcmd.AssertCalledWith(t, 0, "plan", "-no-color")
+	fcmd.AssertAllBehaviorsConsumed(t)
+}
+
+func TestFaker_InProcess_Guard(t *testing.T) {
+	t.Parallel()
+
+	// This test binary behaves as one whose TestMain does not call RunIfInProcess
+	// when FAKECMD_IN_PROCESS_DIR is unset.
+	filePathTestBin, err := os.Executable()
+	require.NoError(t, err)
+	filePathWithoutRunIfInProcess := fmt.Sprintf("/tmp/%s", uuid.NewString())
+	e2ehelpers.MustWriteFile(
+		filePathWithoutRunIfInProcess,
+		[]byte(fmt.Sprintf("#!/bin/sh\nunset FAKECMD_IN_PROCESS_DIR\nexec '%s' \"$@\"\n", filePathTestBin)),
+	)
+	require.NoError(t, os.Chmod(filePathWithoutRunIfInProcess, 0755))
+
+	faker := domains.New(filePathWithoutRunIfInProcess)
+	t.Cleanup(func() {
+		faker.Cleanup()                             //nolint:errcheck
+		os.RemoveAll(filePathWithoutRunIfInProcess) //nolint:errcheck
+	})
+	fcmd := faker.AddInTest(t, domains.Behaviors{}, domains.WithInProcess())
+
+	// The guard fails the test binary loudly instead of running the tests, which invoke the fake command recursively.
+	stderr := strings.Builder{}
+	cmd := exec.Command(fcmd.DirPath().FilePathCommand(), "-chdir=/x", "plan")
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Contains(t, stderr.String(), "flag provided but not defined: -test.fakecmd-requires-RunIfInProcess-in-TestMain")
+}
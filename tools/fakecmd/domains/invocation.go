@@ -0,0 +1,17 @@
+package domains
+
+import "io"
+
+// Invocation is an execution context of the fake command given to behaviors.
+type Invocation struct {
+	// Args is argv excluding the command name.
+	Args []string
+	// Env is environment variables formatted as "KEY=VALUE".
+	Env    []string
+	Cwd    string
+	Stdin  []byte
+	Stdout io.Writer
+	Stderr io.Writer
+	// Index is 0-origin number of the invocation of the fake command.
+	Index int
+}
@@ -0,0 +1,162 @@
+package domains
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// jsonSchemaEnums lists the values of enum types which appear in the behaviors file.
+var jsonSchemaEnums = map[reflect.Type][]any{
+	reflect.TypeFor[BehaviorType]():     toAnys(behaviorTypes),
+	reflect.TypeFor[ArgsMatcherType]():  toAnys(argsMatcherTypes),
+	reflect.TypeFor[StdinMatcherType](): toAnys(stdinMatcherTypes),
+	reflect.TypeFor[ScriptStepType]():   toAnys(scriptStepTypes),
+	reflect.TypeFor[SignalMode]():       toAnys(signalModes),
+	reflect.TypeFor[StreamTarget]():     toAnys(streamTargets),
+	reflect.TypeFor[WhenExhausted]():    toAnys(whenExhausteds),
+}
+
+// behaviorPayloadFields is the field of [Behavior] required by each [BehaviorType].
+var behaviorPayloadFields = []struct {
+	Type  BehaviorType
+	Field string
+}{
+	{BehaviorTypeStdoutStderrExitCode, "BehaviorStdoutStderrExitCode"},
+	{BehaviorTypeScript, "BehaviorScript"},
+	{BehaviorTypePassthrough, "BehaviorPassthrough"},
+	{BehaviorTypeSignal, "BehaviorSignal"},
+	{BehaviorTypeStream, "BehaviorStream"},
+}
+
+// SpecJSONSchema returns the JSON Schema of the behaviors file generated from [Spec].
+func SpecJSONSchema() ([]byte, error) {
+	defs := map[string]any{}
+	spec := jsonSchemaOf(reflect.TypeFor[Spec](), defs)
+	behavior := jsonSchemaOf(reflect.TypeFor[Behavior](), defs)
+
+	// Version is an int in Go but only SpecVersion is supported.
+	specDef := defs["Spec"].(map[string]any)
+	specDef["properties"].(map[string]any)["Version"] = map[string]any{
+		"const": SpecVersion,
+	}
+	specDef["required"] = []any{"Version", "Behaviors"}
+	specDef["if"] = map[string]any{
+		"properties": map[string]any{"WhenExhausted": map[string]any{"const": WhenExhaustedDefault}},
+		"required":   []any{"WhenExhausted"},
+	}
+	specDef["then"] = requiredNonNull("DefaultBehavior", jsonSchemaOf(reflect.TypeFor[Behavior](), defs))
+
+	// Each type requires its payload like Behavior.Validate.
+	behaviorDef := defs["Behavior"].(map[string]any)
+	behaviorDef["required"] = []any{"Type"}
+	allOf := []any{}
+	for _, p := range behaviorPayloadFields {
+		f, _ := reflect.TypeFor[Behavior]().FieldByName(p.Field)
+		allOf = append(allOf, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"Type": map[string]any{"const": p.Type}},
+			},
+			"then": requiredNonNull(p.Field, jsonSchemaOf(f.Type.Elem(), defs)),
+		})
+	}
+	behaviorDef["allOf"] = allOf
+
+	schema := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "fakecmd behaviors file",
+		"oneOf": []any{
+			spec,
+			map[string]any{
+				"description": "legacy format: behaviors of version 1",
+				"type":        "array",
+				"items":       behavior,
+			},
+		},
+		"$defs": defs,
+	}
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to json.MarshalIndent: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// requiredNonNull returns a schema which requires the property of name whose value is not null.
+func requiredNonNull(name string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"required":   []any{name},
+		"properties": map[string]any{name: schema},
+	}
+}
+
+func jsonSchemaOf(t reflect.Type, defs map[string]any) map[string]any {
+	if values, ok := jsonSchemaEnums[t]; ok {
+		return map[string]any{"type": "integer", "enum": values}
+	}
+
+	if t == reflect.TypeFor[time.Duration]() {
+		return map[string]any{"type": "integer", "description": "nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]any{
+			"anyOf": []any{
+				jsonSchemaOf(t.Elem(), defs),
+				map[string]any{"type": "null"},
+			},
+		}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			def := map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+			}
+			// Registered before its fields so that recursive types terminate.
+			defs[t.Name()] = def
+			properties := map[string]any{}
+			for _, f := range reflect.VisibleFields(t) {
+				if !f.IsExported() || f.Anonymous {
+					continue
+				}
+				properties[f.Name] = jsonSchemaOf(f.Type, defs)
+			}
+			def["properties"] = properties
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{
+			"type":  []any{"array", "null"},
+			"items": jsonSchemaOf(t.Elem(), defs),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 []any{"object", "null"},
+			"additionalProperties": jsonSchemaOf(t.Elem(), defs),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Uint8:
+		return map[string]any{"type": "integer", "minimum": 0, "maximum": 255}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	}
+
+	panic(fmt.Sprintf("unsupported type in behaviors file: %s", t))
+}
+
+func toAnys[T any](values []T) []any {
+	ret := make([]any, 0, len(values))
+	for _, v := range values {
+		ret = append(ret, v)
+	}
+	return ret
+}
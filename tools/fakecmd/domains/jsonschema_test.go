@@ -0,0 +1,64 @@
+package domains_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestSpecJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	actual, err := domains.SpecJSONSchema()
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../behaviors.schema.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual), "run `make tools/fakecmd/behaviors.schema.json` to regenerate")
+}
+
+func TestSpecJSONSchema_Required(t *testing.T) {
+	t.Parallel()
+
+	b, err := domains.SpecJSONSchema()
+	require.NoError(t, err)
+
+	schema := struct {
+		Defs map[string]struct {
+			Required []string `json:"required"`
+			AllOf    []struct {
+				If struct {
+					Properties struct {
+						Type struct {
+							Const int `json:"const"`
+						} `json:"Type"`
+					} `json:"properties"`
+				} `json:"if"`
+				Then struct {
+					Required []string `json:"required"`
+				} `json:"then"`
+			} `json:"allOf"`
+		} `json:"$defs"`
+	}{}
+	require.NoError(t, json.Unmarshal(b, &schema))
+
+	assert.Equal(t, []string{"Version", "Behaviors"}, schema.Defs["Spec"].Required)
+	assert.Equal(t, []string{"Type"}, schema.Defs["Behavior"].Required)
+
+	// every behavior type requires its payload
+	payloads := map[int][]string{}
+	for _, c := range schema.Defs["Behavior"].AllOf {
+		payloads[c.If.Properties.Type.Const] = c.Then.Required
+	}
+	assert.Equal(t, map[int][]string{
+		domains.BehaviorTypeStdoutStderrExitCode: {"BehaviorStdoutStderrExitCode"},
+		domains.BehaviorTypeScript:               {"BehaviorScript"},
+		domains.BehaviorTypePassthrough:          {"BehaviorPassthrough"},
+		domains.BehaviorTypeSignal:               {"BehaviorSignal"},
+		domains.BehaviorTypeStream:               {"BehaviorStream"},
+	}, payloads)
+}
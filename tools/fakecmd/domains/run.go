@@ -0,0 +1,342 @@
+package domains
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// ExitCodeFakeCMDError is the exit code of the fake command when the fake command itself fails.
+	ExitCodeFakeCMDError uint8 = 127
+	// ExitCodeStdinMismatch is the exit code of the fake command when stdin does not match [Behavior.StdinMatcher].
+	ExitCodeStdinMismatch uint8 = 125
+)
+
+func newErrorLogger() *log.Logger {
+	prefix := "FAKE_CMD_ERROR "
+	var w io.Writer = os.Stderr
+
+	s := strings.ToLower(os.Getenv("FAKECMD_ERROR_LOG"))
+	switch s {
+	case "discard":
+		w = io.Discard
+	}
+
+	return log.New(w, prefix, 0)
+}
+
+// getLock takes an advisory lock on filePathLock.
+// It waits until the lock is released by other processes up to timeout.
+// The lock is released by the kernel even if the process dies without calling the returned function.
+func getLock(
+	filePathLock string,
+	timeout time.Duration,
+) (func(), error) {
+	fileLock, err := os.OpenFile(filePathLock, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to os.OpenFile: %s: %w", filePathLock, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := syscall.Flock(int(fileLock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			fileLock.Close() //nolint:errcheck
+			return nil, fmt.Errorf("failed to flock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			fileLock.Close() //nolint:errcheck
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return func() {
+		syscall.Flock(int(fileLock.Fd()), syscall.LOCK_UN) //nolint:errcheck
+		fileLock.Close()                                   //nolint:errcheck
+	}, nil
+}
+
+// readStdin reads all of stdin unless stdin is a terminal or a device like /dev/null.
+func readStdin() ([]byte, error) {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to Stat: %w", err)
+	}
+	if fi.Mode()&os.ModeCharDevice != 0 {
+		return []byte{}, nil
+	}
+	return io.ReadAll(os.Stdin)
+}
+
+// Run runs the fake command initialized in dirPath as the current process.
+// args is argv excluding the command name.
+// It returns the exit code of the process.
+func Run(dirPath DirPathFakeCommand, args []string) uint8 {
+	startedAt := time.Now()
+	logger := newErrorLogger()
+
+	spec, err := ReadSpecFile(dirPath.FilePathBehaviors())
+	if err != nil {
+		logger.Printf("%s\n", err)
+		return ExitCodeFakeCMDError
+	}
+	if err := spec.Validate(); err != nil {
+		logger.Printf("invalid behavior file: %s:\n%s\n", dirPath.FilePathBehaviors(), err)
+		return ExitCodeFakeCMDError
+	}
+	if len(spec.Behaviors) <= 0 && spec.DefaultBehavior == nil {
+		logger.Println("no behaviors")
+		return ExitCodeFakeCMDError
+	}
+
+	config, err := ReadConfig(dirPath.FilePathConfig())
+	if err != nil {
+		logger.Printf("%s\n", err)
+		return ExitCodeFakeCMDError
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		logger.Printf("failed to os.Getwd: %s\n", err)
+		return ExitCodeFakeCMDError
+	}
+
+	// The lock is held only while the state is read and written, not while the behavior runs,
+	// so that parallel invocations of a blocking behavior (signal, sleep, passthrough) proceed.
+	withLock := func(f func() error) error {
+		releaseLock, err := getLock(dirPath.FilePathLock(), config.LockTimeoutOrDefault())
+		if err != nil {
+			return fmt.Errorf("failed to get lock: %s: %w", dirPath.FilePathLock(), err)
+		}
+		defer releaseLock()
+		return f()
+	}
+
+	var behaviorIndex int
+	var numHistories int
+	var signalListener *SignalListener
+	history := ExecutedHistory{
+		Args:      args,
+		Cwd:       cwd,
+		Env:       config.FilterEnv(os.Environ()),
+		Stdin:     []byte{},
+		StartedAt: startedAt,
+	}
+	// exitCode is set if the invocation ends before the behavior runs.
+	var exitCode *uint8
+	err = withLock(func() error {
+		state, err := ReadState(dirPath.FilePathState())
+		if err != nil {
+			return err
+		}
+
+		used := state.UsedBehaviorIndexes()
+
+		i, found, err := spec.Select(args, state)
+		if err != nil {
+			return fmt.Errorf("failed to match behaviors: %w", err)
+		}
+		if !found {
+			code := ExitCodeFakeCMDError
+			exitCode = &code
+			if spec.Behaviors.AllUsed(used) {
+				// undefined executions over state.TimesExecuted
+				logger.Printf("all expected executions are done: expected=%d histories=%d\n", len(spec.Behaviors), len(state.ExecutedHistories))
+				return nil
+			}
+			logger.Printf("no behaviors matched: args=%s\n", FormatArgs(args))
+			for _, line := range strings.Split(spec.Behaviors.DescribeCandidates(used), "\n") {
+				logger.Printf("  %s\n", line)
+			}
+			return nil
+		}
+		behaviorIndex = i
+
+		// signals are received before the history is saved, because callers take the saved history
+		// as a sign that the process is ready to receive signals
+		if b := spec.Behavior(i); b.Type == BehaviorTypeSignal && b.BehaviorSignal != nil {
+			signalListener, err = b.BehaviorSignal.Listen()
+			if err != nil {
+				return fmt.Errorf("failed to simulate signal: %w", err)
+			}
+		}
+
+		numHistories = len(state.ExecutedHistories)
+		history.BehaviorIndex = i
+		state.ExecutedHistories = append(state.ExecutedHistories, history)
+		return writeState(dirPath.FilePathState(), state)
+	})
+	if signalListener != nil {
+		defer signalListener.Stop()
+	}
+	if err != nil {
+		logger.Printf("%s\n", err)
+		return ExitCodeFakeCMDError
+	}
+	if exitCode != nil {
+		return *exitCode
+	}
+
+	// updateHistory updates the history of this invocation in the state which other invocations may have updated.
+	updateHistory := func(f func(h *ExecutedHistory)) {
+		err := withLock(func() error {
+			state, err := ReadState(dirPath.FilePathState())
+			if err != nil {
+				return err
+			}
+			if numHistories >= len(state.ExecutedHistories) {
+				return fmt.Errorf("history is lost: %d", numHistories)
+			}
+			f(&state.ExecutedHistories[numHistories])
+			return writeState(dirPath.FilePathState(), state)
+		})
+		if err != nil {
+			logger.Printf("failed to update history: %s\n", err)
+		}
+	}
+
+	closeState := func() {
+		updateHistory(func(h *ExecutedHistory) {
+			h.FinishedAt = time.Now()
+		})
+	}
+
+	behavior := spec.Behavior(behaviorIndex)
+
+	// stdin is not read unless needed because it blocks until the caller closes stdin.
+	stdin := []byte{}
+	if config.CaptureStdin || behavior.UsesStdin() {
+		stdin, err = readStdin()
+		if err != nil {
+			logger.Printf("failed to read stdin: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		updateHistory(func(h *ExecutedHistory) {
+			h.Stdin = stdin
+		})
+	}
+
+	inv := Invocation{
+		Args:   args,
+		Env:    os.Environ(),
+		Cwd:    cwd,
+		Stdin:  stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+		Index:  numHistories,
+	}
+
+	if behavior.StdinMatcher != nil {
+		mismatch, err := behavior.StdinMatcher.Match(stdin)
+		if err != nil {
+			logger.Printf("failed to match stdin: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		if mismatch != "" {
+			closeState()
+			logger.Printf("stdin does not match: behaviors[%d]: %s\n", behaviorIndex, behavior.StdinMatcher.Type)
+			for _, line := range strings.Split(strings.TrimSuffix(mismatch, "\n"), "\n") {
+				logger.Printf("  %s\n", line)
+			}
+			return ExitCodeStdinMismatch
+		}
+	}
+
+	switch behavior.Type {
+	case BehaviorTypeStdoutStderrExitCode:
+		if behavior.BehaviorStdoutStderrExitCode == nil {
+			// type is BehaviorTypeStdoutStderrExitCode but nil
+			return ExitCodeFakeCMDError
+		}
+		defer closeState()
+		exitCode, err := behavior.BehaviorStdoutStderrExitCode.Run(&inv)
+		if err != nil {
+			logger.Printf("failed to write stdout and stderr: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		return exitCode
+	case BehaviorTypeScript:
+		if behavior.BehaviorScript == nil {
+			// type is BehaviorTypeScript but nil
+			return ExitCodeFakeCMDError
+		}
+		defer closeState()
+		exitCode, err := behavior.BehaviorScript.Run(&inv)
+		if err != nil {
+			logger.Printf("failed to run script: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		return exitCode
+	case BehaviorTypePassthrough:
+		if behavior.BehaviorPassthrough == nil {
+			// type is BehaviorTypePassthrough but nil
+			return ExitCodeFakeCMDError
+		}
+		defer closeState()
+		recorded, err := behavior.BehaviorPassthrough.Run(&inv)
+		if err != nil {
+			logger.Printf("failed to pass through: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		if err := withLock(func() error {
+			return AppendRecording(dirPath.FilePathRecordings(), recorded)
+		}); err != nil {
+			logger.Printf("failed to record: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		return recorded.BehaviorStdoutStderrExitCode.ExitCode
+	case BehaviorTypeStream:
+		if behavior.BehaviorStream == nil {
+			// type is BehaviorTypeStream but nil
+			return ExitCodeFakeCMDError
+		}
+		defer closeState()
+		exitCode, err := behavior.BehaviorStream.Run(&inv)
+		if err != nil {
+			logger.Printf("failed to stream: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		return exitCode
+	case BehaviorTypeSignal:
+		if signalListener == nil {
+			// type is BehaviorTypeSignal but nil
+			return ExitCodeFakeCMDError
+		}
+		defer closeState()
+		exitCode, err := signalListener.Wait(func(sig string) {
+			updateHistory(func(h *ExecutedHistory) {
+				h.ReceivedSignals = append(h.ReceivedSignals, sig)
+			})
+		})
+		if err != nil {
+			logger.Printf("failed to simulate signal: %s\n", err)
+			return ExitCodeFakeCMDError
+		}
+		return exitCode
+	default:
+		logger.Printf("unknown behavior type: %d\n", behavior.Type)
+		return ExitCodeFakeCMDError
+	}
+}
+
+func writeState(filePath string, state *State) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to json.Marshal: %w", err)
+	}
+	if err := os.WriteFile(filePath, b, 0755); err != nil {
+		return fmt.Errorf("failed to os.WriteFile: %w", err)
+	}
+	return nil
+}
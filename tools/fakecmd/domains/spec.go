@@ -0,0 +1,147 @@
+package domains
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+type WhenExhausted int
+
+const (
+	// WhenExhaustedFail makes the fake command fail when no unused behavior matches.
+	WhenExhaustedFail WhenExhausted = iota
+	// WhenExhaustedRepeatLast repeats the last behavior when no unused behavior matches.
+	WhenExhaustedRepeatLast
+	// WhenExhaustedCycle uses the matched behavior which has been used the least times when no unused behavior matches.
+	WhenExhaustedCycle
+	// WhenExhaustedDefault uses DefaultBehavior when no unused behavior matches.
+	WhenExhaustedDefault
+)
+
+// SpecVersion is the version of the behaviors file supported by this fakecmd.
+const SpecVersion = 1
+
+// BehaviorIndexDefault is recorded as [ExecutedHistory.BehaviorIndex] when DefaultBehavior is used.
+const BehaviorIndexDefault = -1
+
+// Spec is the content of the behaviors file.
+type Spec struct {
+	Version         int
+	Behaviors       Behaviors
+	WhenExhausted   WhenExhausted
+	DefaultBehavior *Behavior
+}
+
+// UnmarshalJSON rejects unknown fields.
+// It also accepts a JSON array of behaviors of [SpecVersion] for compatibility.
+func (t *Spec) UnmarshalJSON(b []byte) error {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		t.Version = SpecVersion
+		return decodeJSONStrict(b, &t.Behaviors)
+	}
+
+	type alias Spec
+	return decodeJSONStrict(b, (*alias)(t))
+}
+
+func decodeJSONStrict(b []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// Behavior returns the behavior of index which may be [BehaviorIndexDefault].
+func (t *Spec) Behavior(index int) *Behavior {
+	if index == BehaviorIndexDefault {
+		return t.DefaultBehavior
+	}
+	return &t.Behaviors[index]
+}
+
+// Select returns the index of the behavior used for args.
+// It returns false if no behavior is available.
+func (t *Spec) Select(args []string, state *State) (int, bool, error) {
+	i, err := t.Behaviors.FindUnused(args, state.UsedBehaviorIndexes())
+	if err != nil {
+		return 0, false, err
+	}
+	if i >= 0 {
+		return i, true, nil
+	}
+
+	switch t.WhenExhausted {
+	case WhenExhaustedRepeatLast:
+		if len(t.Behaviors) <= 0 {
+			return 0, false, nil
+		}
+		last := len(t.Behaviors) - 1
+		matched, err := t.Behaviors[last].Match(args)
+		if err != nil {
+			return 0, false, fmt.Errorf("behaviors[%d]: %w", last, err)
+		}
+		return last, matched, nil
+	case WhenExhaustedCycle:
+		counts := state.BehaviorUseCounts()
+		found := false
+		for j := range t.Behaviors {
+			matched, err := t.Behaviors[j].Match(args)
+			if err != nil {
+				return 0, false, fmt.Errorf("behaviors[%d]: %w", j, err)
+			}
+			if matched && (!found || counts[j] < counts[i]) {
+				i, found = j, true
+			}
+		}
+		return i, found, nil
+	case WhenExhaustedDefault:
+		if t.DefaultBehavior == nil {
+			return 0, false, nil
+		}
+		matched, err := t.DefaultBehavior.Match(args)
+		if err != nil {
+			return 0, false, fmt.Errorf("default behavior: %w", err)
+		}
+		return BehaviorIndexDefault, matched, nil
+	}
+
+	return 0, false, nil
+}
+
+// ReadSpecFile reads the behaviors file.
+func ReadSpecFile(filePath string) (*Spec, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read behavior file: %s: %w", filePath, err)
+	}
+
+	spec := Spec{}
+	if err := decodeJSONStrict(b, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal behavior file: %s: %w", filePath, err)
+	}
+
+	return &spec, nil
+}
+
+// WriteSpecFile writes the behaviors file.
+func WriteSpecFile(filePath string, spec *Spec) error {
+	b, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to json.MarshalIndent: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, b, 0644); err != nil {
+		return fmt.Errorf("failed to os.WriteFile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package domains_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestSpec_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	spec := domains.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(` [{"Type":1}]`), &spec))
+	assert.Equal(t, domains.Spec{Version: domains.SpecVersion, Behaviors: domains.Behaviors{{Type: 1}}}, spec)
+
+	spec = domains.Spec{}
+	require.NoError(t, json.Unmarshal([]byte(`{"Version":1,"Behaviors":[{"Type":1}],"WhenExhausted":2}`), &spec))
+	assert.Equal(t, domains.Spec{
+		Version:       domains.SpecVersion,
+		Behaviors:     domains.Behaviors{{Type: 1}},
+		WhenExhausted: domains.WhenExhaustedCycle,
+	}, spec)
+
+	spec = domains.Spec{}
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"Version":1,"Behaviors":[{"Type":1,"Stdout":"a"}]}`), &spec), `unknown field "Stdout"`)
+
+	spec = domains.Spec{}
+	require.ErrorContains(t, json.Unmarshal([]byte(`[{"Typo":1}]`), &spec), `unknown field "Typo"`)
+}
+
+func TestReadSpecFile(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "behaviors.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"Version":1} {}`), 0644))
+
+	_, err := domains.ReadSpecFile(filePath)
+	require.ErrorContains(t, err, "failed to unmarshal behavior file: "+filePath+": unexpected data after top-level value")
+}
+
+func TestSpec_Select(t *testing.T) {
+	t.Parallel()
+
+	matcher := func(args ...string) *domains.ArgsMatcher {
+		return &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: args}
+	}
+	histories := func(indexes ...int) *domains.State {
+		state := domains.State{}
+		for _, i := range indexes {
+			state.ExecutedHistories = append(state.ExecutedHistories, domains.ExecutedHistory{BehaviorIndex: i})
+		}
+		return &state
+	}
+
+	testCases := []struct {
+		desc      string
+		spec      domains.Spec
+		args      []string
+		state     *domains.State
+		wantIndex int
+		wantFound bool
+	}{
+		{
+			desc:      "unused behavior is preferred",
+			spec:      domains.Spec{Behaviors: domains.Behaviors{{}, {}}, WhenExhausted: domains.WhenExhaustedRepeatLast},
+			state:     histories(0),
+			wantIndex: 1,
+			wantFound: true,
+		},
+		{
+			desc:      "fail",
+			spec:      domains.Spec{Behaviors: domains.Behaviors{{}, {}}},
+			state:     histories(0, 1),
+			wantFound: false,
+		},
+		{
+			desc:      "repeat last",
+			spec:      domains.Spec{Behaviors: domains.Behaviors{{}, {}}, WhenExhausted: domains.WhenExhaustedRepeatLast},
+			state:     histories(0, 1, 1),
+			wantIndex: 1,
+			wantFound: true,
+		},
+		{
+			desc:      "repeat last - last does not match",
+			spec:      domains.Spec{Behaviors: domains.Behaviors{{}, {ArgsMatcher: matcher("plan")}}, WhenExhausted: domains.WhenExhaustedRepeatLast},
+			args:      []string{"init"},
+			state:     histories(0, 1),
+			wantFound: false,
+		},
+		{
+			desc:      "cycle",
+			spec:      domains.Spec{Behaviors: domains.Behaviors{{}, {}, {}}, WhenExhausted: domains.WhenExhaustedCycle},
+			state:     histories(0, 1, 2, 0),
+			wantIndex: 1,
+			wantFound: true,
+		},
+		{
+			desc: "cycle - only matched behaviors",
+			spec: domains.Spec{
+				Behaviors:     domains.Behaviors{{ArgsMatcher: matcher("init")}, {ArgsMatcher: matcher("plan")}, {ArgsMatcher: matcher("plan")}},
+				WhenExhausted: domains.WhenExhaustedCycle,
+			},
+			args:      []string{"plan"},
+			state:     histories(0, 1, 2, 1),
+			wantIndex: 2,
+			wantFound: true,
+		},
+		{
+			desc: "default",
+			spec: domains.Spec{
+				Behaviors:       domains.Behaviors{{}},
+				WhenExhausted:   domains.WhenExhaustedDefault,
+				DefaultBehavior: &domains.Behavior{},
+			},
+			state:     histories(0),
+			wantIndex: domains.BehaviorIndexDefault,
+			wantFound: true,
+		},
+		{
+			desc: "default - no behaviors",
+			spec: domains.Spec{
+				WhenExhausted:   domains.WhenExhaustedDefault,
+				DefaultBehavior: &domains.Behavior{},
+			},
+			state:     histories(),
+			wantIndex: domains.BehaviorIndexDefault,
+			wantFound: true,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			i, found, err := tC.spec.Select(tC.args, tC.state)
+			require.NoError(t, err)
+			assert.Equal(t, tC.wantFound, found)
+			if tC.wantFound {
+				assert.Equal(t, tC.wantIndex, i)
+			}
+		})
+	}
+}
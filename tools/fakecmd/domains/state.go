@@ -1,10 +1,66 @@
 package domains
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
 type State struct {
 	ExecutedHistories ExecutedHistories
 }
 
+// UsedBehaviorIndexes returns the set of indexes of behaviors which have already been used.
+func (t *State) UsedBehaviorIndexes() map[int]bool {
+	used := map[int]bool{}
+	for _, h := range t.ExecutedHistories {
+		used[h.BehaviorIndex] = true
+	}
+	return used
+}
+
+// ReadState reads state file. It returns empty state if the file does not exist.
+func ReadState(filePath string) (*State, error) {
+	state := State{}
+	b, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return &state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %s: %w", filePath, err)
+	}
+
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state file: %s: %w", filePath, err)
+	}
+
+	return &state, nil
+}
+
+// BehaviorUseCounts returns how many times each behavior has been used.
+func (t *State) BehaviorUseCounts() map[int]int {
+	counts := map[int]int{}
+	for _, h := range t.ExecutedHistories {
+		counts[h.BehaviorIndex]++
+	}
+	return counts
+}
+
 type ExecutedHistories []ExecutedHistory
 
+// ExecutedHistory is a record of an invocation of the fake command.
 type ExecutedHistory struct {
+	// BehaviorIndex is the index of the used behavior or [BehaviorIndexDefault].
+	BehaviorIndex int
+	// Args is argv excluding the command name.
+	Args []string
+	Cwd  string
+	// Env is a snapshot of environment variables filtered by [Config.EnvAllowlist].
+	Env map[string]string
+	// Stdin is recorded only if the used behavior reads stdin or [Config.CaptureStdin] is true.
+	Stdin      []byte
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// ReceivedSignals is names of signals received by [BehaviorTypeSignal] such as "SIGTERM".
+	ReceivedSignals []string
 }
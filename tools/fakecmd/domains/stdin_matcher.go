@@ -0,0 +1,116 @@
+package domains
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+type StdinMatcherType int
+
+const (
+	// StdinMatcherTypeExact matches when stdin equals Content.
+	StdinMatcherTypeExact StdinMatcherType = iota + 1
+	// StdinMatcherTypeRegexp matches when stdin matches the regular expression Content.
+	StdinMatcherTypeRegexp
+	// StdinMatcherTypeJSON matches when stdin and Content are equal as JSON values.
+	StdinMatcherTypeJSON
+)
+
+func (t StdinMatcherType) String() string {
+	switch t {
+	case StdinMatcherTypeExact:
+		return "exact"
+	case StdinMatcherTypeRegexp:
+		return "regexp"
+	case StdinMatcherTypeJSON:
+		return "json"
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
+// StdinMatcher is an expectation on stdin of an invocation.
+// Unlike [ArgsMatcher], it does not select behaviors.
+// The fake command exits with [ExitCodeStdinMismatch] if stdin does not match.
+type StdinMatcher struct {
+	Type    StdinMatcherType
+	Content string
+}
+
+// Match returns a description of the mismatch such as a diff if stdin does not match.
+// It returns empty string if stdin matches.
+func (t *StdinMatcher) Match(stdin []byte) (string, error) {
+	switch t.Type {
+	case StdinMatcherTypeExact:
+		if string(stdin) == t.Content {
+			return "", nil
+		}
+		return diffString(t.Content, string(stdin))
+	case StdinMatcherTypeRegexp:
+		re, err := regexp.Compile(t.Content)
+		if err != nil {
+			return "", fmt.Errorf("failed to regexp.Compile: %s: %w", t.Content, err)
+		}
+		if re.Match(stdin) {
+			return "", nil
+		}
+		return fmt.Sprintf("stdin does not match %q:\n%s", t.Content, stdin), nil
+	case StdinMatcherTypeJSON:
+		var expected any
+		if err := json.Unmarshal([]byte(t.Content), &expected); err != nil {
+			return "", fmt.Errorf("failed to json.Unmarshal Content: %w", err)
+		}
+		var actual any
+		if err := json.Unmarshal(stdin, &actual); err != nil {
+			return fmt.Sprintf("stdin is not JSON: %s:\n%s", err, stdin), nil
+		}
+		if reflect.DeepEqual(expected, actual) {
+			return "", nil
+		}
+		return diffString(indentJSON(t.Content), indentJSON(string(stdin)))
+	}
+	return "", fmt.Errorf("unknown stdin matcher type: %d", t.Type)
+}
+
+func (t *StdinMatcher) Validate() error {
+	switch t.Type {
+	case StdinMatcherTypeExact:
+	case StdinMatcherTypeRegexp:
+		if _, err := regexp.Compile(t.Content); err != nil {
+			return fmt.Errorf("Content: %w", err)
+		}
+	case StdinMatcherTypeJSON:
+		if !json.Valid([]byte(t.Content)) {
+			return fmt.Errorf("Content: invalid JSON")
+		}
+	default:
+		return fmt.Errorf("Type: unknown value %d", t.Type)
+	}
+	return nil
+}
+
+func indentJSON(s string) string {
+	b := bytes.Buffer{}
+	if err := json.Indent(&b, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return b.String()
+}
+
+func diffString(expected string, actual string) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(expected),
+		B:        difflib.SplitLines(actual),
+		FromFile: "Expected",
+		ToFile:   "Actual",
+		Context:  1,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to difflib.GetUnifiedDiffString: %w", err)
+	}
+	return diff, nil
+}
@@ -0,0 +1,84 @@
+package domains_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestStdinMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc         string
+		matcher      domains.StdinMatcher
+		stdin        string
+		wantMismatch string
+		wantErr      string
+	}{
+		{
+			desc:    "exact - ok",
+			matcher: domains.StdinMatcher{Type: domains.StdinMatcherTypeExact, Content: "a\nb\n"},
+			stdin:   "a\nb\n",
+		},
+		{
+			desc:         "exact - ng",
+			matcher:      domains.StdinMatcher{Type: domains.StdinMatcherTypeExact, Content: "a\nb\n"},
+			stdin:        "a\nc\n",
+			wantMismatch: "--- Expected\n+++ Actual\n@@ -1,3 +1,3 @@\n a\n-b\n+c\n \n",
+		},
+		{
+			desc:    "regexp - ok",
+			matcher: domains.StdinMatcher{Type: domains.StdinMatcherTypeRegexp, Content: `^commit message: .+`},
+			stdin:   "commit message: foo",
+		},
+		{
+			desc:         "regexp - ng",
+			matcher:      domains.StdinMatcher{Type: domains.StdinMatcherTypeRegexp, Content: `^commit message: .+`},
+			stdin:        "foo",
+			wantMismatch: "stdin does not match \"^commit message: .+\":\nfoo",
+		},
+		{
+			desc:    "regexp - invalid",
+			matcher: domains.StdinMatcher{Type: domains.StdinMatcherTypeRegexp, Content: `(`},
+			wantErr: "failed to regexp.Compile: (: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			desc:    "json - ok",
+			matcher: domains.StdinMatcher{Type: domains.StdinMatcherTypeJSON, Content: `{"a":1,"b":[true]}`},
+			stdin:   "{\n  \"b\": [true],\n  \"a\": 1.0\n}\n",
+		},
+		{
+			desc:         "json - ng",
+			matcher:      domains.StdinMatcher{Type: domains.StdinMatcherTypeJSON, Content: `{"a":1}`},
+			stdin:        `{"a":2}`,
+			wantMismatch: "--- Expected\n+++ Actual\n@@ -1,3 +1,3 @@\n {\n-  \"a\": 1\n+  \"a\": 2\n }\n",
+		},
+		{
+			desc:         "json - stdin is not json",
+			matcher:      domains.StdinMatcher{Type: domains.StdinMatcherTypeJSON, Content: `{"a":1}`},
+			stdin:        `a`,
+			wantMismatch: "stdin is not JSON: invalid character 'a' looking for beginning of value:\na",
+		},
+		{
+			desc:    "unknown type",
+			matcher: domains.StdinMatcher{},
+			wantErr: "unknown stdin matcher type: 0",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mismatch, err := tC.matcher.Match([]byte(tC.stdin))
+			if tC.wantErr != "" {
+				require.EqualError(t, err, tC.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.wantMismatch, mismatch)
+		})
+	}
+}
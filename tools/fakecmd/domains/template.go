@@ -0,0 +1,57 @@
+package domains
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateContext is data given to templates of behaviors.
+type TemplateContext struct {
+	// Args is argv excluding the command name.
+	Args []string
+	Env  map[string]string
+	Cwd  string
+	// Stdin is the whole stdin of the invocation.
+	Stdin string
+	// Index is 0-origin number of the invocation of the fake command.
+	Index int
+}
+
+func NewTemplateContext(inv *Invocation) *TemplateContext {
+	env := map[string]string{}
+	for _, kv := range inv.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+
+	return &TemplateContext{
+		Args:  inv.Args,
+		Env:   env,
+		Cwd:   inv.Cwd,
+		Stdin: string(inv.Stdin),
+		Index: inv.Index,
+	}
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func executeTemplate(name string, text string, data *TemplateContext) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	b := strings.Builder{}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return b.String(), nil
+}
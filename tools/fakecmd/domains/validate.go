@@ -0,0 +1,220 @@
+package domains
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+var (
+	behaviorTypes = []BehaviorType{
+		BehaviorTypeStdoutStderrExitCode,
+		BehaviorTypeScript,
+		BehaviorTypePassthrough,
+		BehaviorTypeSignal,
+		BehaviorTypeStream,
+	}
+	argsMatcherTypes = []ArgsMatcherType{
+		ArgsMatcherTypeExact,
+		ArgsMatcherTypePrefix,
+		ArgsMatcherTypeRegexp,
+	}
+	stdinMatcherTypes = []StdinMatcherType{
+		StdinMatcherTypeExact,
+		StdinMatcherTypeRegexp,
+		StdinMatcherTypeJSON,
+	}
+	scriptStepTypes = []ScriptStepType{
+		ScriptStepTypeStdout,
+		ScriptStepTypeStderr,
+		ScriptStepTypeCopyStdin,
+		ScriptStepTypeSleep,
+		ScriptStepTypeWriteFile,
+		ScriptStepTypeAppendFile,
+		ScriptStepTypeRemoveFile,
+		ScriptStepTypeExit,
+	}
+	signalModes = []SignalMode{
+		SignalModeWaitForSignal,
+		SignalModeIgnoreSIGTERM,
+		SignalModeKillSelf,
+	}
+	streamTargets = []StreamTarget{
+		StreamTargetStdout,
+		StreamTargetStderr,
+	}
+	whenExhausteds = []WhenExhausted{
+		WhenExhaustedFail,
+		WhenExhaustedRepeatLast,
+		WhenExhaustedCycle,
+		WhenExhaustedDefault,
+	}
+)
+
+// Validate returns all problems of the spec joined by [errors.Join].
+func (t *Spec) Validate() error {
+	errs := []error{}
+
+	if t.Version != SpecVersion {
+		errs = append(errs, fmt.Errorf("Version: unsupported version %d (supported: %d)", t.Version, SpecVersion))
+	}
+
+	for i := range t.Behaviors {
+		errs = append(errs, prefixErr(fmt.Sprintf("Behaviors[%d]", i), t.Behaviors[i].Validate()))
+	}
+
+	if !slices.Contains(whenExhausteds, t.WhenExhausted) {
+		errs = append(errs, fmt.Errorf("WhenExhausted: unknown value %d", t.WhenExhausted))
+	}
+	if t.WhenExhausted == WhenExhaustedDefault && t.DefaultBehavior == nil {
+		errs = append(errs, errors.New("DefaultBehavior: required when WhenExhausted is default"))
+	}
+	if t.DefaultBehavior != nil {
+		errs = append(errs, prefixErr("DefaultBehavior", t.DefaultBehavior.Validate()))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *Behavior) Validate() error {
+	errs := []error{}
+
+	if t.ArgsMatcher != nil {
+		errs = append(errs, prefixErr("ArgsMatcher", t.ArgsMatcher.Validate()))
+	}
+	if t.StdinMatcher != nil {
+		errs = append(errs, prefixErr("StdinMatcher", t.StdinMatcher.Validate()))
+	}
+
+	switch t.Type {
+	case BehaviorTypeStdoutStderrExitCode:
+		if t.BehaviorStdoutStderrExitCode == nil {
+			errs = append(errs, fmt.Errorf("BehaviorStdoutStderrExitCode: required when Type is %s", t.Type))
+		} else {
+			errs = append(errs, prefixErr("BehaviorStdoutStderrExitCode", t.BehaviorStdoutStderrExitCode.Validate()))
+		}
+	case BehaviorTypeScript:
+		if t.BehaviorScript == nil {
+			errs = append(errs, fmt.Errorf("BehaviorScript: required when Type is %s", t.Type))
+		} else {
+			errs = append(errs, prefixErr("BehaviorScript", t.BehaviorScript.Validate()))
+		}
+	case BehaviorTypePassthrough:
+		if t.BehaviorPassthrough == nil {
+			errs = append(errs, fmt.Errorf("BehaviorPassthrough: required when Type is %s", t.Type))
+		} else if t.BehaviorPassthrough.FilePathBin == "" {
+			errs = append(errs, errors.New("BehaviorPassthrough.FilePathBin: required"))
+		} else if !filepath.IsAbs(t.BehaviorPassthrough.FilePathBin) {
+			// a bare name may be resolved to the fake command itself through PATH
+			errs = append(errs, fmt.Errorf("BehaviorPassthrough.FilePathBin: must be an absolute path: %s", t.BehaviorPassthrough.FilePathBin))
+		}
+	case BehaviorTypeSignal:
+		if t.BehaviorSignal == nil {
+			errs = append(errs, fmt.Errorf("BehaviorSignal: required when Type is %s", t.Type))
+		} else {
+			errs = append(errs, prefixErr("BehaviorSignal", t.BehaviorSignal.Validate()))
+		}
+	case BehaviorTypeStream:
+		if t.BehaviorStream == nil {
+			errs = append(errs, fmt.Errorf("BehaviorStream: required when Type is %s", t.Type))
+		} else {
+			errs = append(errs, prefixErr("BehaviorStream", t.BehaviorStream.Validate()))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("Type: unknown value %d", t.Type))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *BehaviorStdoutStderrExitCode) Validate() error {
+	if !t.Template {
+		return nil
+	}
+
+	errs := []error{}
+	if _, err := parseTemplate("Stdout", t.Stdout); err != nil {
+		errs = append(errs, fmt.Errorf("Stdout: %w", err))
+	}
+	if _, err := parseTemplate("Stderr", t.Stderr); err != nil {
+		errs = append(errs, fmt.Errorf("Stderr: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (t *ArgsMatcher) Validate() error {
+	if !slices.Contains(argsMatcherTypes, t.Type) {
+		return fmt.Errorf("Type: unknown value %d", t.Type)
+	}
+
+	if t.Type == ArgsMatcherTypeRegexp {
+		errs := []error{}
+		for i, arg := range t.Args {
+			if _, err := compileArgRegexp(arg); err != nil {
+				errs = append(errs, fmt.Errorf("Args[%d]: %w", i, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (t *BehaviorScript) Validate() error {
+	errs := []error{}
+	for i, step := range t.Steps {
+		if !slices.Contains(scriptStepTypes, step.Type) {
+			errs = append(errs, fmt.Errorf("Steps[%d].Type: unknown value %d", i, step.Type))
+		}
+		switch step.Type {
+		case ScriptStepTypeWriteFile, ScriptStepTypeAppendFile, ScriptStepTypeRemoveFile:
+			if step.Path == "" {
+				errs = append(errs, fmt.Errorf("Steps[%d].Path: required", i))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *BehaviorSignal) Validate() error {
+	if !slices.Contains(signalModes, t.Mode) {
+		return fmt.Errorf("Mode: unknown value %d", t.Mode)
+	}
+
+	if t.Mode == SignalModeKillSelf {
+		if _, ok := signalsByName[t.Signal]; !ok {
+			return fmt.Errorf("Signal: unknown signal %q", t.Signal)
+		}
+	}
+
+	return nil
+}
+
+func (t *BehaviorStream) Validate() error {
+	errs := []error{}
+	for i, chunk := range t.Chunks {
+		if !slices.Contains(streamTargets, chunk.Target) {
+			errs = append(errs, fmt.Errorf("Chunks[%d].Target: unknown value %d", i, chunk.Target))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// prefixErr prefixes each error joined in err with prefix.
+func prefixErr(prefix string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		errs := []error{}
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, prefixErr(prefix, e))
+		}
+		return errors.Join(errs...)
+	}
+
+	return fmt.Errorf("%s.%w", prefix, err)
+}
@@ -0,0 +1,155 @@
+package domains_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+)
+
+func TestSpec_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc   string
+		spec   domains.Spec
+		errMsg string
+	}{
+		{
+			desc: "ok",
+			spec: domains.Spec{
+				Version: domains.SpecVersion,
+				Behaviors: domains.Behaviors{
+					{
+						Type:                         domains.BehaviorTypeStdoutStderrExitCode,
+						ArgsMatcher:                  &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"^pl"}},
+						BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{},
+					},
+					{
+						Type:           domains.BehaviorTypeScript,
+						BehaviorScript: &domains.BehaviorScript{Steps: []domains.ScriptStep{{Type: domains.ScriptStepTypeWriteFile, Path: "a"}}},
+					},
+					{
+						Type:                domains.BehaviorTypePassthrough,
+						BehaviorPassthrough: &domains.BehaviorPassthrough{FilePathBin: "/bin/echo"},
+					},
+					{
+						Type:           domains.BehaviorTypeSignal,
+						BehaviorSignal: &domains.BehaviorSignal{Mode: domains.SignalModeKillSelf, Signal: "SIGKILL"},
+					},
+					{
+						Type:           domains.BehaviorTypeStream,
+						BehaviorStream: &domains.BehaviorStream{Chunks: []domains.StreamChunk{{Target: domains.StreamTargetStderr}}},
+					},
+				},
+			},
+		},
+		{
+			desc:   "ng - version",
+			spec:   domains.Spec{Version: 2},
+			errMsg: "Version: unsupported version 2 (supported: 1)",
+		},
+		{
+			desc: "ng - passthrough to a bare name",
+			spec: domains.Spec{
+				Version: domains.SpecVersion,
+				Behaviors: domains.Behaviors{
+					{
+						Type:                domains.BehaviorTypePassthrough,
+						BehaviorPassthrough: &domains.BehaviorPassthrough{FilePathBin: "terraform"},
+					},
+				},
+			},
+			errMsg: "Behaviors[0].BehaviorPassthrough.FilePathBin: must be an absolute path: terraform",
+		},
+		{
+			desc: "ng - template",
+			spec: domains.Spec{
+				Version: domains.SpecVersion,
+				Behaviors: domains.Behaviors{
+					{
+						Type: domains.BehaviorTypeStdoutStderrExitCode,
+						BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+							Stdout:   "{{.Stdin}",
+							Stderr:   "{{if}}",
+							Template: true,
+						},
+					},
+					{
+						Type: domains.BehaviorTypeStdoutStderrExitCode,
+						// not parsed without Template
+						BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{Stdout: "{{.Stdin}"},
+					},
+				},
+			},
+			errMsg: "Behaviors[0].BehaviorStdoutStderrExitCode.Stdout: failed to parse template: template: Stdout:1: bad character U+007D '}'\n" +
+				"Behaviors[0].BehaviorStdoutStderrExitCode.Stderr: failed to parse template: template: Stderr:1: missing value for if",
+		},
+		{
+			desc: "ng - kill self with a signal which does not terminate a Go process",
+			spec: domains.Spec{
+				Version: domains.SpecVersion,
+				Behaviors: domains.Behaviors{
+					{
+						Type:           domains.BehaviorTypeSignal,
+						BehaviorSignal: &domains.BehaviorSignal{Mode: domains.SignalModeKillSelf, Signal: "SIGUSR1"},
+					},
+				},
+			},
+			errMsg: "Behaviors[0].BehaviorSignal.Signal: unknown signal \"SIGUSR1\"",
+		},
+		{
+			desc: "ng - all problems are reported",
+			spec: domains.Spec{
+				Version: domains.SpecVersion,
+				Behaviors: domains.Behaviors{
+					{Type: 9},
+					{
+						Type:         domains.BehaviorTypeStdoutStderrExitCode,
+						ArgsMatcher:  &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeRegexp, Args: []string{"("}},
+						StdinMatcher: &domains.StdinMatcher{Type: domains.StdinMatcherTypeJSON, Content: "{"},
+					},
+					{
+						Type:           domains.BehaviorTypeScript,
+						BehaviorScript: &domains.BehaviorScript{Steps: []domains.ScriptStep{{}, {Type: domains.ScriptStepTypeRemoveFile}}},
+					},
+					{
+						Type:                domains.BehaviorTypePassthrough,
+						BehaviorPassthrough: &domains.BehaviorPassthrough{},
+					},
+					{
+						Type:           domains.BehaviorTypeSignal,
+						BehaviorSignal: &domains.BehaviorSignal{Mode: domains.SignalModeKillSelf, Signal: "SIGFOO"},
+					},
+					{
+						Type:           domains.BehaviorTypeStream,
+						BehaviorStream: &domains.BehaviorStream{Chunks: []domains.StreamChunk{{}}},
+					},
+				},
+				WhenExhausted: domains.WhenExhaustedDefault,
+			},
+			errMsg: "Behaviors[0].Type: unknown value 9\n" +
+				"Behaviors[1].ArgsMatcher.Args[0]: error parsing regexp: missing closing ): `(`\n" +
+				"Behaviors[1].StdinMatcher.Content: invalid JSON\n" +
+				"Behaviors[1].BehaviorStdoutStderrExitCode: required when Type is stdoutStderrExitCode\n" +
+				"Behaviors[2].BehaviorScript.Steps[0].Type: unknown value 0\n" +
+				"Behaviors[2].BehaviorScript.Steps[1].Path: required\n" +
+				"Behaviors[3].BehaviorPassthrough.FilePathBin: required\n" +
+				"Behaviors[4].BehaviorSignal.Signal: unknown signal \"SIGFOO\"\n" +
+				"Behaviors[5].BehaviorStream.Chunks[0].Target: unknown value 0\n" +
+				"DefaultBehavior: required when WhenExhausted is default",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := tC.spec.Validate()
+			if tC.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tC.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package httpfakeserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// APIError is returned by [Client] when the admin API responds an unexpected status.
+// Message is the body of the response, like "invalid mock: ...".
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("http error: status=%d body=%s", e.StatusCode, e.Message)
+}
+
+// waitForRequestInterval is how often [Client.WaitForRequest] polls recorded requests.
+const waitForRequestInterval = 50 * time.Millisecond
+
+// Client is a client for the admin API of httpfakeserver.
+type Client struct {
+	baseURLAdmin string
+	client       *http.Client
+}
+
+// NewClient returns a new [Client] given base URL of hosted httpfakeserver, base path of admin endpoints
+// (like /admin) and http client.
+func NewClient(
+	baseURL *url.URL,
+	basePathAdmin string,
+	client *http.Client,
+) *Client {
+	return &Client{
+		baseURLAdmin: baseURL.JoinPath(basePathAdmin).String(),
+		client:       client,
+	}
+}
+
+// ForTest returns a [Client] which operates on the namespace of testID.
+// See [BaseURLAdminForTest].
+func (t *Client) ForTest(testID string) *Client {
+	return &Client{
+		baseURLAdmin: BaseURLAdminForTest(t.baseURLAdmin, testID),
+		client:       t.client,
+	}
+}
+
+// AddMock requests POST <admin>/cases.
+// A mock of the same request replaces the existing one.
+func (t *Client) AddMock(ctx context.Context, m Mock) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURLAdmin+"/cases", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = doAdminRequest(t.client, req, http.StatusNoContent)
+	return err
+}
+
+// AddMocks adds mocks in order. It stops at the first error and the mocks before it remain added.
+func (t *Client) AddMocks(ctx context.Context, mocks Mocks) error {
+	for i, m := range mocks {
+		if err := t.AddMock(ctx, m); err != nil {
+			return fmt.Errorf("mocks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ListMocks requests GET <admin>/cases.
+func (t *Client) ListMocks(ctx context.Context) (Mocks, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURLAdmin+"/cases", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to http.NewRequest: %w", err)
+	}
+
+	body, err := doAdminRequest(t.client, req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := Mocks{}
+	if err := json.Unmarshal(body, &ret); err != nil {
+		return nil, fmt.Errorf("failed to json.Unmarshal: %w", err)
+	}
+	return ret, nil
+}
+
+// Reset removes mocks and recorded requests.
+func (t *Client) Reset(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.baseURLAdmin+"/cases", nil)
+	if err != nil {
+		return fmt.Errorf("failed to http.NewRequest: %w", err)
+	}
+
+	if _, err := doAdminRequest(t.client, req, http.StatusNoContent); err != nil {
+		return err
+	}
+
+	return DeleteRequests(ctx, t.client, t.baseURLAdmin)
+}
+
+// Requests returns recorded requests matching filter. See [GetRequests].
+func (t *Client) Requests(ctx context.Context, filter RequestsFilter) (RecordedRequests, error) {
+	return GetRequests(ctx, t.client, t.baseURLAdmin, filter)
+}
+
+// WaitForRequest polls recorded requests until one matching filter is recorded and returns the first of them.
+// It returns the error of ctx if ctx is done before that.
+func (t *Client) WaitForRequest(ctx context.Context, filter RequestsFilter) (RecordedRequest, error) {
+	ticker := time.NewTicker(waitForRequestInterval)
+	defer ticker.Stop()
+	for {
+		requests, err := t.Requests(ctx, filter)
+		if err != nil {
+			return RecordedRequest{}, err
+		}
+		if len(requests) > 0 {
+			return requests[0], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return RecordedRequest{}, fmt.Errorf("failed to wait for request: %w", context.Cause(ctx))
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package httpfakeserver_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver"
+)
+
+func newClient(t *testing.T, handler http.HandlerFunc) *httpfakeserver.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return httpfakeserver.NewClient(u, "/admin", server.Client())
+}
+
+func TestClientAddMocks(t *testing.T) {
+	paths := []string{}
+	cli := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if len(paths) >= 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "invalid mock: TEST") //nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := cli.ForTest("t1").AddMocks(context.Background(), httpfakeserver.Mocks{{}, {}, {}})
+
+	assert.EqualError(t, err, "mocks[1]: http error: status=400 body=invalid mock: TEST")
+	apiErr := &httpfakeserver.APIError{}
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, &httpfakeserver.APIError{StatusCode: http.StatusBadRequest, Message: "invalid mock: TEST"}, apiErr)
+	assert.Equal(t, []string{"POST /admin/tests/t1/cases", "POST /admin/tests/t1/cases"}, paths)
+}
+
+func TestClientWaitForRequest(t *testing.T) {
+	polled := atomic.Int32{}
+	cli := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/admin/requests", r.URL.Path)
+		assert.Equal(t, "path=%2Ffoo", r.URL.RawQuery)
+		if polled.Add(1) < 3 {
+			fmt.Fprintf(w, "[]") //nolint:errcheck
+			return
+		}
+		fmt.Fprintf(w, `[{"method":"GET","url":"/foo"}]`) //nolint:errcheck
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		actual, err := cli.WaitForRequest(context.Background(), httpfakeserver.RequestsFilter{Path: "/foo"})
+		require.NoError(t, err)
+		assert.Equal(t, "/foo", actual.URL)
+		assert.Equal(t, int32(3), polled.Load())
+	})
+
+	t.Run("ng - timeout", func(t *testing.T) {
+		polled.Store(-100)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := cli.WaitForRequest(ctx, httpfakeserver.RequestsFilter{Path: "/foo"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -26,10 +26,22 @@ func main() {
 		basePathAdmin = "/admin"
 	}
 
+	watchMocksDir := false
+	if s := os.Getenv("WATCH_MOCKS_DIR"); s != "" {
+		var err error
+		watchMocksDir, err = strconv.ParseBool(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to convert WATCH_MOCKS_DIR into bool\n")
+			os.Exit(1)
+		}
+	}
+
 	ctx := context.Background()
 
 	os.Exit(httpfakeserver.Main(ctx, httpfakeserver.Options{
 		Port:          port,
 		BasePathAdmin: basePathAdmin,
+		MocksDir:      os.Getenv("MOCKS_DIR"),
+		WatchMocksDir: watchMocksDir,
 	}))
 }
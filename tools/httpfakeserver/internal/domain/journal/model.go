@@ -0,0 +1,133 @@
+package journal
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// MockIDUnmatched is [Entry.MockID] of a request which matched no mocks.
+const MockIDUnmatched = "unmatched"
+
+// Entry is a request received by the fake server.
+type Entry struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+	// MockID is ID of the matched mock or [MockIDUnmatched].
+	MockID string `json:"mockId"`
+	// PathVars is variables captured from the path by the matched mock.
+	PathVars   map[string]string `json:"pathVars,omitempty"`
+	ReceivedAt time.Time         `json:"receivedAt"`
+	// TestID is the value of E2E-TestId header of the request.
+	TestID string `json:"testId,omitempty"`
+}
+
+// Path returns the path of URL.
+func (e *Entry) Path() string {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// Query returns the query of URL.
+func (e *Entry) Query() url.Values {
+	u, err := url.Parse(e.URL)
+	if err != nil {
+		return url.Values{}
+	}
+	return u.Query()
+}
+
+func (e *Entry) Matched() bool {
+	return e.MockID != MockIDUnmatched
+}
+
+type Entries []Entry
+
+// Filter is a condition on entries. Empty fields match any entries.
+type Filter struct {
+	Method string
+	Path   string
+	MockID string
+	// Query matches entries which have all of the query parameters.
+	Query url.Values
+}
+
+func (f *Filter) Match(e *Entry) bool {
+	if f.Method != "" && f.Method != e.Method {
+		return false
+	}
+	if f.Path != "" && f.Path != e.Path() {
+		return false
+	}
+	if f.MockID != "" && f.MockID != e.MockID {
+		return false
+	}
+	if len(f.Query) > 0 {
+		query := e.Query()
+		for k, vs := range f.Query {
+			for _, v := range vs {
+				if !hasValue(query[k], v) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func hasValue(vs []string, v string) bool {
+	for _, vv := range vs {
+		if vv == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Values returns f as query parameters of the admin endpoint.
+// Query of f is encoded as "query" parameters like query=key%3Dvalue.
+func (f *Filter) Values() url.Values {
+	values := url.Values{}
+	if f.Method != "" {
+		values.Set("method", f.Method)
+	}
+	if f.Path != "" {
+		values.Set("path", f.Path)
+	}
+	if f.MockID != "" {
+		values.Set("mockId", f.MockID)
+	}
+	for k, vs := range f.Query {
+		for _, v := range vs {
+			values.Add("query", url.Values{k: []string{v}}.Encode())
+		}
+	}
+	return values
+}
+
+// NewFilterFromValues is the inverse of [Filter.Values].
+func NewFilterFromValues(values url.Values) (*Filter, error) {
+	f := Filter{
+		Method: values.Get("method"),
+		Path:   values.Get("path"),
+		MockID: values.Get("mockId"),
+	}
+	for _, q := range values["query"] {
+		parsed, err := url.ParseQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		if f.Query == nil {
+			f.Query = url.Values{}
+		}
+		for k, vs := range parsed {
+			f.Query[k] = append(f.Query[k], vs...)
+		}
+	}
+	return &f, nil
+}
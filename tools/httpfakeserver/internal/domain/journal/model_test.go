@@ -0,0 +1,51 @@
+package journal
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatch(t *testing.T) {
+	e := Entry{
+		Method: "PUT",
+		URL:    "/repos/o/r/pulls/1/merge?merge_method=squash&x=1",
+		MockID: "mock1",
+	}
+
+	testCases := []struct {
+		desc   string
+		filter Filter
+		want   bool
+	}{
+		{desc: "empty filter", filter: Filter{}, want: true},
+		{desc: "method", filter: Filter{Method: "PUT"}, want: true},
+		{desc: "different method", filter: Filter{Method: "GET"}, want: false},
+		{desc: "path", filter: Filter{Path: "/repos/o/r/pulls/1/merge"}, want: true},
+		{desc: "different path", filter: Filter{Path: "/repos/o/r/pulls/1"}, want: false},
+		{desc: "mock id", filter: Filter{MockID: "mock1"}, want: true},
+		{desc: "unmatched", filter: Filter{MockID: MockIDUnmatched}, want: false},
+		{desc: "query", filter: Filter{Query: url.Values{"merge_method": {"squash"}}}, want: true},
+		{desc: "different query", filter: Filter{Query: url.Values{"merge_method": {"merge"}}}, want: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.want, tC.filter.Match(&e))
+		})
+	}
+}
+
+func TestFilterValues(t *testing.T) {
+	f := Filter{
+		Method: "PUT",
+		Path:   "/merge",
+		MockID: MockIDUnmatched,
+		Query:  url.Values{"merge_method": {"squash"}},
+	}
+
+	actual, err := NewFilterFromValues(f.Values())
+	require.NoError(t, err)
+	assert.Equal(t, &f, actual)
+}
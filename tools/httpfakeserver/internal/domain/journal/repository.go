@@ -0,0 +1,48 @@
+package journal
+
+import (
+	"sync"
+)
+
+type Repository struct {
+	entriesMu sync.Mutex
+	entries   Entries
+}
+
+func (m *Repository) Add(e Entry) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	m.entries = append(m.entries, e)
+}
+
+// Clear removes entries of testID.
+func (m *Repository) Clear(testID string) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	remained := Entries{}
+	for _, e := range m.entries {
+		if e.TestID != testID {
+			remained = append(remained, e)
+		}
+	}
+	m.entries = remained
+}
+
+// Entries returns entries of testID matching f in the order of receipt.
+func (m *Repository) Entries(testID string, f *Filter) Entries {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	ret := Entries{}
+	for i := range m.entries {
+		if m.entries[i].TestID == testID && f.Match(&m.entries[i]) {
+			ret = append(ret, m.entries[i])
+		}
+	}
+	return ret
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		entries: Entries{},
+	}
+}
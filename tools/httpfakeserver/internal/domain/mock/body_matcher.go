@@ -0,0 +1,163 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+)
+
+type BodyMatcherType string
+
+const (
+	// BodyMatcherTypeExact matches when the body equals Value.
+	BodyMatcherTypeExact BodyMatcherType = "exact"
+	// BodyMatcherTypeJSON matches when the body and Value are equal as JSON values.
+	BodyMatcherTypeJSON BodyMatcherType = "json"
+	// BodyMatcherTypeJSONPath matches when the body is JSON and satisfies all of JSONPath.
+	BodyMatcherTypeJSONPath BodyMatcherType = "jsonpath"
+	// BodyMatcherTypeRegexp matches when the body matches the regular expression Value.
+	BodyMatcherTypeRegexp BodyMatcherType = "regexp"
+)
+
+// BodyMatcher matches the body of a request.
+type BodyMatcher struct {
+	Type  BodyMatcherType `json:"type"`
+	Value string          `json:"value,omitempty"`
+	// JSONPath is used when Type is jsonpath.
+	JSONPath []JSONPathPredicate `json:"jsonPath,omitempty"`
+}
+
+// JSONPathPredicate is satisfied when at least one value selected by Path satisfies it.
+// If both Equals and Matches are empty, it is satisfied when Path selects some value.
+type JSONPathPredicate struct {
+	Path string `json:"path"`
+	// Equals is compared with the selected value as a JSON value.
+	Equals json.RawMessage `json:"equals,omitempty"`
+	// Matches is a regular expression which the selected string value matches.
+	Matches string `json:"matches,omitempty"`
+}
+
+func (m *BodyMatcher) Validate() error {
+	switch m.Type {
+	case BodyMatcherTypeExact:
+	case BodyMatcherTypeJSON:
+		if !json.Valid([]byte(m.Value)) {
+			return errors.New("value is not valid JSON")
+		}
+	case BodyMatcherTypeRegexp:
+		if _, err := regexp.Compile(m.Value); err != nil {
+			return fmt.Errorf("value is not valid regexp: %w", err)
+		}
+	case BodyMatcherTypeJSONPath:
+		if len(m.JSONPath) <= 0 {
+			return errors.New("jsonPath is empty")
+		}
+		errs := []error{}
+		for i, p := range m.JSONPath {
+			if err := p.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("jsonPath[%d]: %w", i, err))
+			}
+		}
+		return errors.Join(errs...)
+	default:
+		return fmt.Errorf("unknown type: %q", m.Type)
+	}
+	return nil
+}
+
+func (m *BodyMatcher) Match(body []byte) bool {
+	switch m.Type {
+	case BodyMatcherTypeExact:
+		return string(body) == m.Value
+	case BodyMatcherTypeJSON:
+		return jsonEqual([]byte(m.Value), body)
+	case BodyMatcherTypeRegexp:
+		re, err := regexp.Compile(m.Value)
+		if err != nil {
+			return false
+		}
+		return re.Match(body)
+	case BodyMatcherTypeJSONPath:
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return false
+		}
+		for _, p := range m.JSONPath {
+			if !p.match(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (p *JSONPathPredicate) validate() error {
+	if _, err := parseJSONPath(p.Path); err != nil {
+		return fmt.Errorf("path is invalid: %w", err)
+	}
+	if len(p.Equals) > 0 && !json.Valid(p.Equals) {
+		return errors.New("equals is not valid JSON")
+	}
+	if _, err := regexp.Compile(p.Matches); err != nil {
+		return fmt.Errorf("matches is not valid regexp: %w", err)
+	}
+	return nil
+}
+
+func (p *JSONPathPredicate) match(v any) bool {
+	segments, err := parseJSONPath(p.Path)
+	if err != nil {
+		return false
+	}
+	re, err := regexp.Compile(p.Matches)
+	if err != nil {
+		return false
+	}
+
+	for _, selected := range selectJSONPath(v, segments) {
+		if len(p.Equals) > 0 {
+			b, err := json.Marshal(selected)
+			if err != nil || !jsonEqual(p.Equals, b) {
+				continue
+			}
+		}
+		if p.Matches != "" {
+			s, ok := selected.(string)
+			if !ok || !re.MatchString(s) {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func jsonEqual(a []byte, b []byte) bool {
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// readBody reads the body of r and restores it so that it can be read again.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return []byte{}, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,157 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyMatcherMatch(t *testing.T) {
+	body := `{"pr": 1, "comment": {"body": "LGTM", "tags": ["a", "b"]}}`
+
+	testCases := []struct {
+		desc    string
+		matcher BodyMatcher
+		body    string
+		want    bool
+	}{
+		{
+			desc:    "exact - matched",
+			matcher: BodyMatcher{Type: BodyMatcherTypeExact, Value: "foo"},
+			body:    "foo",
+			want:    true,
+		},
+		{
+			desc:    "exact - not matched",
+			matcher: BodyMatcher{Type: BodyMatcherTypeExact, Value: "foo"},
+			body:    "foo ",
+			want:    false,
+		},
+		{
+			desc:    "json - matched regardless of key order and spaces",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSON, Value: `{"comment":{"tags":["a","b"],"body":"LGTM"},"pr":1.0}`},
+			body:    body,
+			want:    true,
+		},
+		{
+			desc:    "json - not matched",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSON, Value: `{"pr":2}`},
+			body:    `{"pr":1}`,
+			want:    false,
+		},
+		{
+			desc:    "json - body is not json",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSON, Value: `{}`},
+			body:    `foo`,
+			want:    false,
+		},
+		{
+			desc:    "regexp - matched",
+			matcher: BodyMatcher{Type: BodyMatcherTypeRegexp, Value: `"body": "LG.M"`},
+			body:    body,
+			want:    true,
+		},
+		{
+			desc: "jsonpath - matched",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSONPath, JSONPath: []JSONPathPredicate{
+				{Path: "$.pr", Equals: json.RawMessage(`1`)},
+				{Path: "$.comment.body", Matches: "^LG"},
+				{Path: "$['comment'].tags[*]", Equals: json.RawMessage(`"b"`)},
+				{Path: "$.comment.tags[1]"},
+			}},
+			body: body,
+			want: true,
+		},
+		{
+			desc: "jsonpath - equals not matched",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSONPath, JSONPath: []JSONPathPredicate{
+				{Path: "$.pr", Equals: json.RawMessage(`2`)},
+			}},
+			body: body,
+			want: false,
+		},
+		{
+			desc: "jsonpath - path does not exist",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSONPath, JSONPath: []JSONPathPredicate{
+				{Path: "$.comment.tags[2]"},
+			}},
+			body: body,
+			want: false,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.want, tC.matcher.Match([]byte(tC.body)))
+		})
+	}
+}
+
+func TestBodyMatcherValidate(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		matcher BodyMatcher
+		errMsg  string
+	}{
+		{
+			desc:    "ok",
+			matcher: BodyMatcher{Type: BodyMatcherTypeExact},
+		},
+		{
+			desc:    "unknown type",
+			matcher: BodyMatcher{Type: "foo"},
+			errMsg:  `unknown type: "foo"`,
+		},
+		{
+			desc:    "invalid json",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSON, Value: "{"},
+			errMsg:  "value is not valid JSON",
+		},
+		{
+			desc:    "invalid regexp",
+			matcher: BodyMatcher{Type: BodyMatcherTypeRegexp, Value: "("},
+			errMsg:  "value is not valid regexp: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			desc:    "empty jsonpath",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSONPath},
+			errMsg:  "jsonPath is empty",
+		},
+		{
+			desc: "invalid jsonpath",
+			matcher: BodyMatcher{Type: BodyMatcherTypeJSONPath, JSONPath: []JSONPathPredicate{
+				{Path: "pr"},
+				{Path: "$.a[x]"},
+				{Path: "$.a", Matches: "("},
+			}},
+			errMsg: "jsonPath[0]: path is invalid: must start with $: pr\n" +
+				"jsonPath[1]: path is invalid: invalid index \"x\": $.a[x]\n" +
+				"jsonPath[2]: matches is not valid regexp: error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.matcher.Validate()
+			if tC.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tC.errMsg)
+			}
+		})
+	}
+}
+
+func TestMockMatchBody(t *testing.T) {
+	m1 := Mock{Request: Request{Method: http.MethodPost, Path: "/comments", Body: &BodyMatcher{Type: BodyMatcherTypeExact, Value: "a"}}}
+	m2 := Mock{Request: Request{Method: http.MethodPost, Path: "/comments", Body: &BodyMatcher{Type: BodyMatcherTypeExact, Value: "b"}}}
+	assert.NotEqual(t, m1.ID(), m2.ID())
+
+	r, err := http.NewRequest(http.MethodPost, "/comments", strings.NewReader("b"))
+	require.NoError(t, err)
+	assert.False(t, m1.Match(r))
+	// the body can be read again after Match
+	assert.True(t, m2.Match(r))
+}
@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Fault makes a [Response] slow or broken to simulate an unhealthy upstream.
+type Fault struct {
+	// DelayMs delays the response in milliseconds.
+	DelayMs int `json:"delayMs,omitempty"`
+	// DelayJitterMs adds a random delay in [0, DelayJitterMs) milliseconds to DelayMs.
+	DelayJitterMs int `json:"delayJitterMs,omitempty"`
+	// DropConnection closes the connection without writing the status line and headers.
+	DropConnection bool `json:"dropConnection,omitempty"`
+	// TruncateBodyAt closes the connection after writing this many bytes of the body
+	// while Content-Length tells the whole length.
+	TruncateBodyAt *int `json:"truncateBodyAt,omitempty"`
+	// ChunkSize makes the body chunked. Each chunk of ChunkSize bytes is flushed
+	// and followed by a pause of ChunkIntervalMs milliseconds.
+	ChunkSize       int `json:"chunkSize,omitempty"`
+	ChunkIntervalMs int `json:"chunkIntervalMs,omitempty"`
+}
+
+func (f *Fault) Validate() error {
+	errs := []error{}
+	if f.DelayMs < 0 {
+		errs = append(errs, fmt.Errorf("delayMs must not be negative: %d", f.DelayMs))
+	}
+	if f.DelayJitterMs < 0 {
+		errs = append(errs, fmt.Errorf("delayJitterMs must not be negative: %d", f.DelayJitterMs))
+	}
+	if f.TruncateBodyAt != nil && *f.TruncateBodyAt < 0 {
+		errs = append(errs, fmt.Errorf("truncateBodyAt must not be negative: %d", *f.TruncateBodyAt))
+	}
+	if f.ChunkSize < 0 {
+		errs = append(errs, fmt.Errorf("chunkSize must not be negative: %d", f.ChunkSize))
+	}
+	if f.ChunkIntervalMs < 0 {
+		errs = append(errs, fmt.Errorf("chunkIntervalMs must not be negative: %d", f.ChunkIntervalMs))
+	}
+	if f.ChunkIntervalMs > 0 && f.ChunkSize <= 0 {
+		errs = append(errs, errors.New("chunkIntervalMs requires chunkSize"))
+	}
+	if f.TruncateBodyAt != nil && f.ChunkSize > 0 {
+		errs = append(errs, errors.New("truncateBodyAt and chunkSize are exclusive"))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Fault) delay() time.Duration {
+	d := time.Duration(f.DelayMs) * time.Millisecond
+	if f.DelayJitterMs > 0 {
+		d += time.Duration(rand.N(f.DelayJitterMs)) * time.Millisecond
+	}
+	return d
+}
+
+// sleep returns false if r is canceled while sleeping.
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// abortConnection makes the server close the connection after flushing what has been written.
+func abortConnection(w http.ResponseWriter) {
+	http.NewResponseController(w).Flush() //nolint:errcheck
+	panic(http.ErrAbortHandler)
+}
+
+func (r Response) writeWithFault(w http.ResponseWriter, req *http.Request) {
+	f := r.Fault
+	if !sleep(req, f.delay()) {
+		return
+	}
+	if f.DropConnection {
+		// Not flushed so that nothing is written.
+		panic(http.ErrAbortHandler)
+	}
+
+	r.writeHeader(w)
+	body := []byte(r.Body)
+	switch {
+	case f.TruncateBodyAt != nil:
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(r.Status)
+		w.Write(body[:min(*f.TruncateBodyAt, len(body))]) //nolint:errcheck
+		abortConnection(w)
+	case f.ChunkSize > 0:
+		w.WriteHeader(r.Status)
+		rc := http.NewResponseController(w)
+		for len(body) > 0 {
+			n := min(f.ChunkSize, len(body))
+			if _, err := w.Write(body[:n]); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			body = body[n:]
+			if len(body) > 0 && !sleep(req, time.Duration(f.ChunkIntervalMs)*time.Millisecond) {
+				return
+			}
+		}
+	default:
+		w.WriteHeader(r.Status)
+		w.Write(body) //nolint:errcheck
+	}
+}
@@ -0,0 +1,96 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseWriteWithFault(t *testing.T) {
+	truncateAt := 5
+	testCases := []struct {
+		desc         string
+		input        Response
+		expectedBody string
+		wantErr      bool
+		minElapsed   time.Duration
+	}{
+		{
+			desc:         "delay",
+			input:        Response{Status: 200, Body: "hello", Fault: &Fault{DelayMs: 100, DelayJitterMs: 10}},
+			expectedBody: "hello",
+			minElapsed:   100 * time.Millisecond,
+		},
+		{
+			desc:    "drop connection",
+			input:   Response{Status: 200, Body: "hello", Fault: &Fault{DropConnection: true}},
+			wantErr: true,
+		},
+		{
+			desc:         "truncate body",
+			input:        Response{Status: 200, Body: "hello world", Fault: &Fault{TruncateBodyAt: &truncateAt}},
+			expectedBody: "hello",
+			wantErr:      true,
+		},
+		{
+			desc:         "slow chunks",
+			input:        Response{Status: 200, Body: "hello world", Fault: &Fault{ChunkSize: 4, ChunkIntervalMs: 50}},
+			expectedBody: "hello world",
+			minElapsed:   100 * time.Millisecond,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tC.input.Write(w, r)
+			}))
+			defer server.Close()
+
+			start := time.Now()
+			res, err := http.Get(server.URL)
+			if err != nil {
+				require.True(t, tC.wantErr, err)
+				return
+			}
+			defer res.Body.Close() //nolint:errcheck
+
+			body, err := io.ReadAll(res.Body)
+			assert.Equal(t, tC.wantErr, err != nil, err)
+			assert.Equal(t, tC.expectedBody, string(body))
+			assert.GreaterOrEqual(t, time.Since(start), tC.minElapsed)
+		})
+	}
+}
+
+func TestFaultValidate(t *testing.T) {
+	truncateAt := 1
+	testCases := []struct {
+		desc    string
+		input   Fault
+		wantErr string
+	}{
+		{desc: "ok", input: Fault{DelayMs: 1, DelayJitterMs: 1, ChunkSize: 1, ChunkIntervalMs: 1}},
+		{desc: "ng - negative delay", input: Fault{DelayMs: -1}, wantErr: "delayMs must not be negative: -1"},
+		{desc: "ng - interval without size", input: Fault{ChunkIntervalMs: 1}, wantErr: "chunkIntervalMs requires chunkSize"},
+		{
+			desc:    "ng - truncate and chunk",
+			input:   Fault{TruncateBodyAt: &truncateAt, ChunkSize: 1},
+			wantErr: "truncateBodyAt and chunkSize are exclusive",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.input.Validate()
+			if tC.wantErr != "" {
+				assert.EqualError(t, err, tC.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
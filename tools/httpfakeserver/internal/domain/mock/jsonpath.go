@@ -0,0 +1,99 @@
+package mock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment is a segment of a JSONPath.
+// key is used for a member of an object, index for an element of an array.
+// wildcard selects all members or elements.
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses a subset of JSONPath:
+// $, .key, ['key'], ["key"], [0], [*] and .*
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("must start with $: %s", path)
+	}
+
+	segments := []jsonPathSegment{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			switch key {
+			case "":
+				return nil, fmt.Errorf("empty key: %s", path)
+			case "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			default:
+				segments = append(segments, jsonPathSegment{key: key})
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [: %s", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid index %q: %s", inner, path)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected character %q: %s", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
+
+// selectJSONPath returns values selected by segments from v decoded by encoding/json.
+func selectJSONPath(v any, segments []jsonPathSegment) []any {
+	values := []any{v}
+	for _, seg := range segments {
+		next := []any{}
+		for _, value := range values {
+			switch vv := value.(type) {
+			case map[string]any:
+				if seg.wildcard {
+					for _, e := range vv {
+						next = append(next, e)
+					}
+				} else if e, ok := vv[seg.key]; ok && !seg.isIndex {
+					next = append(next, e)
+				}
+			case []any:
+				if seg.wildcard {
+					next = append(next, vv...)
+				} else if seg.isIndex && seg.index < len(vv) {
+					next = append(next, vv[seg.index])
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
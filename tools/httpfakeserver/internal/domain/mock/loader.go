@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mocksFileExts are the extensions of files loaded by [LoadMocksDir].
+var mocksFileExts = []string{".json", ".yaml", ".yml"}
+
+// LoadMocksDir loads every mocks file directly under dir.
+// Each file is a list of [Mock] in JSON or YAML whose keys are the same as JSON.
+// All files are checked and the errors of them are returned together.
+func LoadMocksDir(dir string) (Mocks, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to os.ReadDir: %w", err)
+	}
+
+	mocks := Mocks{}
+	errs := []error{}
+	for _, e := range entries {
+		if e.IsDir() || !IsMocksFile(e.Name()) {
+			continue
+		}
+		filePath := filepath.Join(dir, e.Name())
+		ms, err := LoadMocksFile(filePath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filePath, err))
+			continue
+		}
+		mocks = append(mocks, ms...)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return mocks, nil
+}
+
+// IsMocksFile returns true if the file is loaded by [LoadMocksDir].
+func IsMocksFile(name string) bool {
+	return slices.Contains(mocksFileExts, filepath.Ext(name))
+}
+
+// LoadMocksFile loads a mocks file and validates the mocks in it.
+func LoadMocksFile(filePath string) (Mocks, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to os.ReadFile: %w", err)
+	}
+
+	if filepath.Ext(filePath) != ".json" {
+		// YAML is converted to JSON so that keys and types follow the json tags.
+		var v any
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("failed to yaml.Unmarshal: %w", err)
+		}
+		b, err = json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to json.Marshal: %w", err)
+		}
+	}
+
+	mocks := Mocks{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&mocks); err != nil {
+		return nil, fmt.Errorf("failed to decode: %w", err)
+	}
+
+	errs := []error{}
+	for i, m := range mocks {
+		if err := m.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("[%d]: %w", i, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return mocks, nil
+}
@@ -0,0 +1,85 @@
+package mock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMocksDir(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		files    map[string]string
+		expected Mocks
+		wantErr  []string
+	}{
+		{
+			desc: "ok",
+			files: map[string]string{
+				"a.json": `[{"request": {"method": "GET", "path": "/a"}, "response": {"status": 200, "body": "a"}}]`,
+				"b.yaml": `
+- request:
+    method: POST
+    path: /b/{id}
+    pathMatcher: pattern
+  response:
+    status: 201
+    header:
+      Content-Type: [application/json]
+  times: 1
+`,
+				"c.txt": `not a mocks file`,
+			},
+			expected: Mocks{
+				{
+					Request:  Request{Method: "GET", Path: "/a"},
+					Response: Response{Status: 200, Body: "a"},
+				},
+				{
+					Request:  Request{Method: "POST", Path: "/b/{id}", PathMatcher: PathMatcherTypePattern},
+					Response: Response{Status: 201, Header: map[string][]string{"Content-Type": {"application/json"}}},
+					Times:    1,
+				},
+			},
+		},
+		{
+			desc:     "ok - empty",
+			files:    map[string]string{},
+			expected: Mocks{},
+		},
+		{
+			desc: "ng - all files are checked",
+			files: map[string]string{
+				"a.json": `[{"request": {"path": "/a"}, "unknown": 1}]`,
+				"b.yml":  "- request:\n    path: /a\n    pathMatcher: foo\n",
+				"c.yaml": "- request: [",
+			},
+			wantErr: []string{
+				`a.json: failed to decode: json: unknown field "unknown"`,
+				`b.yml: [0]: request: path: unknown pathMatcher: "foo"`,
+				`c.yaml: failed to yaml.Unmarshal: `,
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tC.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			actual, err := LoadMocksDir(dir)
+			if len(tC.wantErr) > 0 {
+				for _, e := range tC.wantErr {
+					assert.ErrorContains(t, err, e)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, actual)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"maps"
@@ -14,15 +16,38 @@ type Request struct {
 	Path   string      `json:"path"`
 	Header http.Header `json:"header"`
 	Query  url.Values  `json:"query"`
+	// PathMatcher is how Path is matched. Empty means [PathMatcherTypeExact].
+	PathMatcher PathMatcherType `json:"pathMatcher,omitempty"`
+	// Body is optional. A request without Body matches any body.
+	Body *BodyMatcher `json:"body,omitempty"`
 }
 
 func (r *Request) ID() string {
 	header := strings.Builder{}
 	r.Header.Write(&header) // nolint:errcheck
 	src := strings.ToLower(r.Method) + r.Path + header.String() + r.Query.Encode()
+	if r.PathMatcher.orDefault() != PathMatcherTypeExact {
+		src += string(r.PathMatcher)
+	}
+	if r.Body != nil {
+		body, _ := json.Marshal(r.Body)
+		src += string(body)
+	}
 	return src
 }
 
+func (r *Request) Validate() error {
+	if err := validatePath(r.PathMatcher, r.Path); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+	if r.Body != nil {
+		if err := r.Body.Validate(); err != nil {
+			return fmt.Errorf("body: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *Request) Equal(rr *Request) bool {
 	return r.ID() == rr.ID()
 }
@@ -54,31 +79,166 @@ type Response struct {
 	Header http.Header `json:"header"`
 	Body   string      `json:"body"`
 	Status int         `json:"status"`
+	// Template makes Body and values of Header Go templates rendered with [TemplateData].
+	Template bool `json:"template,omitempty"`
+	// Fault is optional. It delays or breaks the response.
+	Fault *Fault `json:"fault,omitempty"`
+}
+
+func (r Response) Write(w http.ResponseWriter, req *http.Request) {
+	if r.Fault != nil {
+		r.writeWithFault(w, req)
+		return
+	}
+	r.writeHeader(w)
+	w.WriteHeader(r.Status)
+	fmt.Fprint(w, r.Body) //nolint:errcheck
+}
+
+func (r Response) writeHeader(w http.ResponseWriter) {
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			w.Header().Set(k, v)
+		}
+	}
+}
+
+func (r Response) isZero() bool {
+	return len(r.Header) <= 0 && r.Body == "" && r.Status == 0 && !r.Template && r.Fault == nil
+}
+
+func (r Response) validate() error {
+	if err := r.validateTemplate(); err != nil {
+		return err
+	}
+	if r.Fault != nil {
+		if err := r.Fault.Validate(); err != nil {
+			return fmt.Errorf("fault: %w", err)
+		}
+	}
+	return nil
 }
 
+type WhenExhausted string
+
+const (
+	// WhenExhaustedRepeatLast repeats the last of Responses after all of them are used.
+	WhenExhaustedRepeatLast WhenExhausted = "repeatLast"
+	// WhenExhaustedFail responds an error after all of Responses are used.
+	WhenExhaustedFail WhenExhausted = "fail"
+)
+
 type Mock struct {
 	Request  Request  `json:"request"`
 	Response Response `json:"response"`
+	// Responses are used in order instead of Response if not empty.
+	Responses []Response `json:"responses,omitempty"`
+	// WhenExhausted is what the mock does after all of Responses are used.
+	// Empty means [WhenExhaustedRepeatLast].
+	WhenExhausted WhenExhausted `json:"whenExhausted,omitempty"`
+	// Times is how many times the mock matches at most. Zero means unlimited.
+	// A mock which has been used Times times no longer matches requests.
+	Times int `json:"times,omitempty"`
+	// Consumed is how many times the mock has matched.
+	// It is reported by the admin API and ignored when the mock is posted.
+	Consumed int `json:"consumed,omitempty"`
 }
 
 func (c Mock) ID() string {
 	return c.Request.ID()
 }
 
+func (c Mock) Validate() error {
+	if err := c.Request.Validate(); err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	if len(c.Responses) > 0 && !c.Response.isZero() {
+		return errors.New("response and responses are exclusive")
+	}
+	if err := c.Response.validate(); err != nil {
+		return fmt.Errorf("response: %w", err)
+	}
+	for i, res := range c.Responses {
+		if err := res.validate(); err != nil {
+			return fmt.Errorf("responses[%d]: %w", i, err)
+		}
+	}
+	switch c.WhenExhausted {
+	case "", WhenExhaustedRepeatLast, WhenExhaustedFail:
+	default:
+		return fmt.Errorf("unknown whenExhausted: %q", c.WhenExhausted)
+	}
+	if c.Times < 0 {
+		return fmt.Errorf("times must not be negative: %d", c.Times)
+	}
+	return nil
+}
+
+// ResponseAt returns the response for the n-th (0-origin) match of the mock.
+// It returns false if Responses are exhausted and WhenExhausted is [WhenExhaustedFail].
+func (c Mock) ResponseAt(n int) (Response, bool) {
+	if len(c.Responses) <= 0 {
+		return c.Response, true
+	}
+	if n < len(c.Responses) {
+		return c.Responses[n], true
+	}
+	if c.WhenExhausted == WhenExhaustedFail {
+		return Response{}, false
+	}
+	return c.Responses[len(c.Responses)-1], true
+}
+
 func (c Mock) Match(r *http.Request) bool {
+	_, matched := c.MatchWithPathVars(r)
+	return matched
+}
+
+// MatchWithPathVars is like [Mock.Match] but also returns variables captured from the path.
+func (c Mock) MatchWithPathVars(r *http.Request) (map[string]string, bool) {
+	pathVars, matched := matchPath(c.Request.PathMatcher, c.Request.Path, r.URL.Path)
+	if !matched {
+		return nil, false
+	}
+
 	headerKeys := maps.Keys(c.Request.Header)
 	rr := NewRequestFromHTTPRequest(r, headerKeys)
-	return c.Request.Equal(rr)
+	// Equal compares requests excluding the path and the body.
+	rr.Path = c.Request.Path
+	rr.PathMatcher = c.Request.PathMatcher
+	rr.Body = c.Request.Body
+	if !c.Request.Equal(rr) {
+		return nil, false
+	}
+
+	if c.Request.Body == nil {
+		return pathVars, true
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return nil, false
+	}
+	if !c.Request.Body.Match(body) {
+		return nil, false
+	}
+	return pathVars, true
 }
 
-func (c Mock) WriteResponse(w http.ResponseWriter) {
-	for k, vs := range c.Response.Header {
-		for _, v := range vs {
-			w.Header().Set(k, v)
-		}
+// compareMocksByPriority orders mocks so that a more specific mock is used first
+// when several mocks match a request: exact paths, patterns, globs and then regexps.
+// Among mocks of the same kind, a mock with fewer path variables or wildcards comes first.
+func compareMocksByPriority(a Mock, b Mock) int {
+	if d := pathMatcherPriorities[a.Request.PathMatcher.orDefault()] - pathMatcherPriorities[b.Request.PathMatcher.orDefault()]; d != 0 {
+		return d
 	}
-	w.WriteHeader(c.Response.Status)
-	fmt.Fprint(w, c.Response.Body) //nolint:errcheck
+	if d := numWildcards(a.Request.PathMatcher, a.Request.Path) - numWildcards(b.Request.PathMatcher, b.Request.Path); d != 0 {
+		return d
+	}
+	return strings.Compare(a.ID(), b.ID())
+}
+
+func (c Mock) WriteResponse(w http.ResponseWriter, r *http.Request) {
+	c.Response.Write(w, r)
 }
 
 type Mocks []Mock
@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type PathMatcherType string
+
+const (
+	// PathMatcherTypeExact matches when the path equals Path. It is the default.
+	PathMatcherTypeExact PathMatcherType = "exact"
+	// PathMatcherTypePattern matches net/http style patterns like /repos/{owner}/{repo}/pulls/{number}.
+	// {name...} at the end matches the rest of the path.
+	PathMatcherTypePattern PathMatcherType = "pattern"
+	// PathMatcherTypeGlob matches glob patterns of [path.Match] like /repos/*/*/pulls/*.
+	PathMatcherTypeGlob PathMatcherType = "glob"
+	// PathMatcherTypeRegexp matches when the whole path matches the regular expression.
+	// Named groups are captured as path variables.
+	PathMatcherTypeRegexp PathMatcherType = "regexp"
+)
+
+// pathMatcherPriorities orders mocks when several mocks match a request.
+// A mock of a smaller priority is used first.
+var pathMatcherPriorities = map[PathMatcherType]int{
+	PathMatcherTypeExact:   0,
+	PathMatcherTypePattern: 1,
+	PathMatcherTypeGlob:    2,
+	PathMatcherTypeRegexp:  3,
+}
+
+func (t PathMatcherType) orDefault() PathMatcherType {
+	if t == "" {
+		return PathMatcherTypeExact
+	}
+	return t
+}
+
+func validatePath(t PathMatcherType, p string) error {
+	switch t.orDefault() {
+	case PathMatcherTypeExact:
+	case PathMatcherTypePattern:
+		if _, err := parsePathPattern(p); err != nil {
+			return err
+		}
+	case PathMatcherTypeGlob:
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid glob: %w", err)
+		}
+	case PathMatcherTypeRegexp:
+		if _, err := compilePathRegexp(p); err != nil {
+			return fmt.Errorf("invalid regexp: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown pathMatcher: %q", t)
+	}
+	return nil
+}
+
+// matchPath returns variables captured from actual if actual matches p.
+func matchPath(t PathMatcherType, p string, actual string) (map[string]string, bool) {
+	switch t.orDefault() {
+	case PathMatcherTypeExact:
+		return map[string]string{}, p == actual
+	case PathMatcherTypePattern:
+		segments, err := parsePathPattern(p)
+		if err != nil {
+			return nil, false
+		}
+		return matchPathPattern(segments, actual)
+	case PathMatcherTypeGlob:
+		matched, err := path.Match(p, actual)
+		return map[string]string{}, err == nil && matched
+	case PathMatcherTypeRegexp:
+		re, err := compilePathRegexp(p)
+		if err != nil {
+			return nil, false
+		}
+		m := re.FindStringSubmatch(actual)
+		if m == nil {
+			return nil, false
+		}
+		vars := map[string]string{}
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				vars[name] = m[i]
+			}
+		}
+		return vars, true
+	}
+	return nil, false
+}
+
+// compilePathRegexp compiles p so that it matches only a whole path.
+// p is compiled as it is first so that errors refer to p.
+func compilePathRegexp(p string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(p); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(`^(?:` + p + `)$`)
+}
+
+// numWildcards returns how many parts of p match variable strings.
+// A mock with fewer wildcards is more specific among mocks of the same [PathMatcherType].
+func numWildcards(t PathMatcherType, p string) int {
+	switch t.orDefault() {
+	case PathMatcherTypePattern:
+		segments, _ := parsePathPattern(p)
+		n := 0
+		for _, seg := range segments {
+			if seg.name != "" {
+				n++
+			}
+		}
+		return n
+	case PathMatcherTypeGlob:
+		return strings.Count(p, "*") + strings.Count(p, "?") + strings.Count(p, "[")
+	}
+	return 0
+}
+
+type pathPatternSegment struct {
+	literal string
+	name    string
+	rest    bool
+}
+
+var regexpPathVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func parsePathPattern(p string) ([]pathPatternSegment, error) {
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("pattern must start with /: %s", p)
+	}
+
+	parts := strings.Split(p[1:], "/")
+	segments := make([]pathPatternSegment, 0, len(parts))
+	names := map[string]bool{}
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			if strings.ContainsAny(part, "{}") {
+				return nil, fmt.Errorf("variable must be a whole segment: %s", p)
+			}
+			segments = append(segments, pathPatternSegment{literal: part})
+			continue
+		}
+
+		name := part[1 : len(part)-1]
+		rest := strings.HasSuffix(name, "...")
+		if rest {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("{%s} must be at the end: %s", name, p)
+			}
+			name = strings.TrimSuffix(name, "...")
+		}
+		if !regexpPathVariableName.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name %q: %s", name, p)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicated variable name %q: %s", name, p)
+		}
+		names[name] = true
+		segments = append(segments, pathPatternSegment{name: name, rest: rest})
+	}
+
+	return segments, nil
+}
+
+func matchPathPattern(segments []pathPatternSegment, actual string) (map[string]string, bool) {
+	if !strings.HasPrefix(actual, "/") {
+		return nil, false
+	}
+
+	parts := strings.Split(actual[1:], "/")
+	vars := map[string]string{}
+	for i, seg := range segments {
+		if seg.rest {
+			if i >= len(parts) {
+				return nil, false
+			}
+			vars[seg.name] = strings.Join(parts[i:], "/")
+			return vars, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		if seg.name == "" {
+			if parts[i] != seg.literal {
+				return nil, false
+			}
+			continue
+		}
+		if parts[i] == "" {
+			return nil, false
+		}
+		vars[seg.name] = parts[i]
+	}
+	if len(parts) != len(segments) {
+		return nil, false
+	}
+
+	return vars, true
+}
@@ -0,0 +1,125 @@
+package mock
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		typ      PathMatcherType
+		path     string
+		actual   string
+		wantVars map[string]string
+		want     bool
+	}{
+		{desc: "exact", typ: "", path: "/a/b", actual: "/a/b", wantVars: map[string]string{}, want: true},
+		{desc: "exact - not matched", typ: PathMatcherTypeExact, path: "/a/b", actual: "/a/c", want: false},
+		{
+			desc:     "pattern",
+			typ:      PathMatcherTypePattern,
+			path:     "/repos/{owner}/{repo}/pulls/{number}",
+			actual:   "/repos/o/r/pulls/12",
+			wantVars: map[string]string{"owner": "o", "repo": "r", "number": "12"},
+			want:     true,
+		},
+		{desc: "pattern - shorter", typ: PathMatcherTypePattern, path: "/repos/{owner}/{repo}", actual: "/repos/o", want: false},
+		{desc: "pattern - longer", typ: PathMatcherTypePattern, path: "/repos/{owner}", actual: "/repos/o/r", want: false},
+		{desc: "pattern - empty segment", typ: PathMatcherTypePattern, path: "/repos/{owner}", actual: "/repos/", want: false},
+		{
+			desc:     "pattern - rest",
+			typ:      PathMatcherTypePattern,
+			path:     "/files/{path...}",
+			actual:   "/files/a/b.txt",
+			wantVars: map[string]string{"path": "a/b.txt"},
+			want:     true,
+		},
+		{desc: "pattern - rest needs a slash", typ: PathMatcherTypePattern, path: "/files/{path...}", actual: "/files", want: false},
+		{desc: "glob", typ: PathMatcherTypeGlob, path: "/repos/*/*/pulls/*", actual: "/repos/o/r/pulls/1", wantVars: map[string]string{}, want: true},
+		{desc: "glob - * does not match /", typ: PathMatcherTypeGlob, path: "/repos/*", actual: "/repos/o/r", want: false},
+		{
+			desc:     "regexp",
+			typ:      PathMatcherTypeRegexp,
+			path:     `/repos/[^/]+/[^/]+/issues/(?P<number>\d+)/comments`,
+			actual:   "/repos/o/r/issues/3/comments",
+			wantVars: map[string]string{"number": "3"},
+			want:     true,
+		},
+		{desc: "regexp - whole path", typ: PathMatcherTypeRegexp, path: `/repos`, actual: "/repos/o", want: false},
+		{desc: "regexp - alternation", typ: PathMatcherTypeRegexp, path: `/pulls|/pulls/\d+`, actual: "/pulls/12", want: true, wantVars: map[string]string{}},
+		{desc: "regexp - lazy quantifier", typ: PathMatcherTypeRegexp, path: `/pulls/\d+?`, actual: "/pulls/12", want: true, wantVars: map[string]string{}},
+		{desc: "regexp - alternation is anchored", typ: PathMatcherTypeRegexp, path: `/pulls|/issues`, actual: "/pulls/12", want: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			vars, matched := matchPath(tC.typ, tC.path, tC.actual)
+			assert.Equal(t, tC.want, matched)
+			if tC.want {
+				assert.Equal(t, tC.wantVars, vars)
+			}
+		})
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		typ    PathMatcherType
+		path   string
+		errMsg string
+	}{
+		{desc: "ok", typ: PathMatcherTypePattern, path: "/a/{b}/{c...}"},
+		{desc: "unknown", typ: "foo", path: "/a", errMsg: `unknown pathMatcher: "foo"`},
+		{desc: "pattern - not start with /", typ: PathMatcherTypePattern, path: "a", errMsg: "pattern must start with /: a"},
+		{desc: "pattern - partial", typ: PathMatcherTypePattern, path: "/a{b}", errMsg: "variable must be a whole segment: /a{b}"},
+		{desc: "pattern - rest not at the end", typ: PathMatcherTypePattern, path: "/{a...}/b", errMsg: "{a...} must be at the end: /{a...}/b"},
+		{desc: "pattern - duplicated", typ: PathMatcherTypePattern, path: "/{a}/{a}", errMsg: `duplicated variable name "a": /{a}/{a}`},
+		{desc: "glob", typ: PathMatcherTypeGlob, path: "/[", errMsg: "invalid glob: syntax error in pattern"},
+		{desc: "regexp", typ: PathMatcherTypeRegexp, path: "(", errMsg: "invalid regexp: error parsing regexp: missing closing ): `(`"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := validatePath(tC.typ, tC.path)
+			if tC.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tC.errMsg)
+			}
+		})
+	}
+}
+
+func TestRepositoryMocksPriority(t *testing.T) {
+	regexpMock := Mock{Request: Request{Method: "GET", PathMatcher: PathMatcherTypeRegexp, Path: `/repos/.*`}}
+	globMock := Mock{Request: Request{Method: "GET", PathMatcher: PathMatcherTypeGlob, Path: "/repos/*/*/pulls/*"}}
+	pattern2Mock := Mock{Request: Request{Method: "GET", PathMatcher: PathMatcherTypePattern, Path: "/repos/{owner}/{repo}/pulls/{number}"}}
+	pattern1Mock := Mock{Request: Request{Method: "GET", PathMatcher: PathMatcherTypePattern, Path: "/repos/o/{repo}/pulls/{number}"}}
+	exactMock := Mock{Request: Request{Method: "GET", Path: "/repos/o/r/pulls/1"}}
+
+	repo := NewRepository()
+	for _, m := range []Mock{regexpMock, globMock, pattern2Mock, pattern1Mock, exactMock} {
+		repo.SetMock(TestIDShared, m)
+	}
+
+	assert.Equal(
+		t,
+		[]Mock{exactMock, pattern1Mock, pattern2Mock, globMock, regexpMock},
+		slices.Collect(repo.Mocks(TestIDShared)),
+	)
+
+	r, err := http.NewRequest(http.MethodGet, "/repos/o/r/pulls/2", nil)
+	require.NoError(t, err)
+	for m := range repo.Mocks(TestIDShared) {
+		vars, matched := m.MatchWithPathVars(r)
+		if matched {
+			assert.Equal(t, pattern1Mock, m)
+			assert.Equal(t, map[string]string{"repo": "r", "number": "2"}, vars)
+			break
+		}
+	}
+}
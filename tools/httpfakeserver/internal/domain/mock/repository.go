@@ -7,37 +7,108 @@ import (
 	"sync"
 )
 
+// HeaderNameTestID is the header which identifies the namespace of mocks.
+// It is set by e2ehelpers.RoundTripperForE2E.
+const HeaderNameTestID = "E2E-TestId"
+
+// TestIDShared is the test ID of the shared namespace.
+// Mocks in the shared namespace are used by requests of any test IDs as a fallback.
+const TestIDShared = ""
+
 type Repository struct {
 	casesMu sync.Mutex
-	cases   map[string]Mock
+	// cases is mocks keyed by test ID and then by mock ID.
+	// Consumed of each mock is counted up by [Repository.Consume].
+	cases map[string]map[string]Mock
+}
+
+// SetMock adds c into the namespace of testID.
+// A mock of the same ID is replaced and its consumption count is reset.
+func (m *Repository) SetMock(testID string, c Mock) {
+	m.casesMu.Lock()
+	defer m.casesMu.Unlock()
+	if _, exists := m.cases[testID]; !exists {
+		m.cases[testID] = map[string]Mock{}
+	}
+	c.Consumed = 0
+	m.cases[testID][c.ID()] = c
 }
 
-func (m *Repository) SetMock(c Mock) {
+// ReplaceMocks removes mocks of oldIDs from the namespace of testID and adds mocks at once.
+// Other mocks in the namespace are kept.
+func (m *Repository) ReplaceMocks(testID string, oldIDs []string, mocks Mocks) {
 	m.casesMu.Lock()
 	defer m.casesMu.Unlock()
-	m.cases[c.ID()] = c
+	if _, exists := m.cases[testID]; !exists {
+		m.cases[testID] = map[string]Mock{}
+	}
+	for _, id := range oldIDs {
+		delete(m.cases[testID], id)
+	}
+	for _, c := range mocks {
+		c.Consumed = 0
+		m.cases[testID][c.ID()] = c
+	}
 }
 
-func (m *Repository) Clear() {
+// Clear removes mocks in the namespace of testID.
+func (m *Repository) Clear(testID string) {
 	m.casesMu.Lock()
 	defer m.casesMu.Unlock()
-	m.cases = map[string]Mock{}
+	delete(m.cases, testID)
 }
 
-func (m *Repository) Mocks() iter.Seq[Mock] {
-	return func(yield func(Mock) bool) {
-		m.casesMu.Lock()
-		defer m.casesMu.Unlock()
-		for _, key := range slices.Sorted(maps.Keys(m.cases)) {
-			if !yield(m.cases[key]) {
-				break
+// Mocks returns mocks in the namespace of testID in the order of priority.
+// See [compareMocksByPriority].
+func (m *Repository) Mocks(testID string) iter.Seq[Mock] {
+	m.casesMu.Lock()
+	mocks := slices.Collect(maps.Values(m.cases[testID]))
+	m.casesMu.Unlock()
+
+	slices.SortFunc(mocks, compareMocksByPriority)
+	return slices.Values(mocks)
+}
+
+// Candidates returns mocks which a request of testID may match together with the test ID of their namespace.
+// Mocks in the namespace of testID come first, followed by mocks in the shared namespace.
+func (m *Repository) Candidates(testID string) iter.Seq2[string, Mock] {
+	return func(yield func(string, Mock) bool) {
+		for c := range m.Mocks(testID) {
+			if !yield(testID, c) {
+				return
+			}
+		}
+		if testID == TestIDShared {
+			return
+		}
+		for c := range m.Mocks(TestIDShared) {
+			if !yield(TestIDShared, c) {
+				return
 			}
 		}
 	}
 }
 
+// Consume counts up how many times the mock of mockID in the namespace of testID has matched.
+// It returns the count before counting up, or false if the mock has already matched Times times or does not exist.
+func (m *Repository) Consume(testID string, mockID string) (int, bool) {
+	m.casesMu.Lock()
+	defer m.casesMu.Unlock()
+	c, exists := m.cases[testID][mockID]
+	if !exists {
+		return 0, false
+	}
+	if c.Times > 0 && c.Consumed >= c.Times {
+		return 0, false
+	}
+	n := c.Consumed
+	c.Consumed++
+	m.cases[testID][mockID] = c
+	return n, true
+}
+
 func NewRepository() *Repository {
 	return &Repository{
-		cases: map[string]Mock{},
+		cases: map[string]map[string]Mock{},
 	}
 }
@@ -0,0 +1,100 @@
+package mock
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryNamespaces(t *testing.T) {
+	repo := NewRepository()
+	shared := Mock{Request: Request{Path: "/shared"}}
+	test1 := Mock{Request: Request{Path: "/test1"}}
+	test2 := Mock{Request: Request{Path: "/test2"}}
+	repo.SetMock(TestIDShared, shared)
+	repo.SetMock("test1", test1)
+	repo.SetMock("test2", test2)
+
+	candidates := func(testID string) []string {
+		ret := []string{}
+		for namespace, c := range repo.Candidates(testID) {
+			ret = append(ret, namespace+":"+c.Request.Path)
+		}
+		return ret
+	}
+
+	assert.Equal(t, []Mock{test1}, slices.Collect(repo.Mocks("test1")))
+	assert.Equal(t, []string{"test1:/test1", ":/shared"}, candidates("test1"))
+	assert.Equal(t, []string{":/shared"}, candidates(TestIDShared))
+	assert.Equal(t, []string{":/shared"}, candidates("unknown"))
+
+	repo.Clear("test1")
+	assert.Empty(t, slices.Collect(repo.Mocks("test1")))
+	assert.Equal(t, []Mock{test2}, slices.Collect(repo.Mocks("test2")))
+	assert.Equal(t, []Mock{shared}, slices.Collect(repo.Mocks(TestIDShared)))
+}
+
+func TestRepositoryConsume(t *testing.T) {
+	repo := NewRepository()
+	c := Mock{Request: Request{Path: "/foo"}, Times: 2}
+	repo.SetMock("test1", c)
+
+	n, ok := repo.Consume("test1", c.ID())
+	assert.Equal(t, 0, n)
+	assert.True(t, ok)
+	n, ok = repo.Consume("test1", c.ID())
+	assert.Equal(t, 1, n)
+	assert.True(t, ok)
+	_, ok = repo.Consume("test1", c.ID())
+	assert.False(t, ok)
+	_, ok = repo.Consume(TestIDShared, c.ID())
+	assert.False(t, ok)
+
+	assert.Equal(t, 2, slices.Collect(repo.Mocks("test1"))[0].Consumed)
+
+	// posting the same mock again resets the count
+	repo.SetMock("test1", c)
+	n, ok = repo.Consume("test1", c.ID())
+	assert.Equal(t, 0, n)
+	assert.True(t, ok)
+}
+
+func TestMockResponseAt(t *testing.T) {
+	r1 := Response{Status: 200, Body: `{"mergeable":null}`}
+	r2 := Response{Status: 200, Body: `{"mergeable":true}`}
+
+	testCases := []struct {
+		desc   string
+		mock   Mock
+		n      int
+		want   Response
+		wantOK bool
+	}{
+		{desc: "single response", mock: Mock{Response: r1}, n: 5, want: r1, wantOK: true},
+		{desc: "first", mock: Mock{Responses: []Response{r1, r2}}, n: 0, want: r1, wantOK: true},
+		{desc: "second", mock: Mock{Responses: []Response{r1, r2}}, n: 1, want: r2, wantOK: true},
+		{desc: "repeat last", mock: Mock{Responses: []Response{r1, r2}}, n: 2, want: r2, wantOK: true},
+		{desc: "fail", mock: Mock{Responses: []Response{r1, r2}, WhenExhausted: WhenExhaustedFail}, n: 2, wantOK: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, ok := tC.mock.ResponseAt(tC.n)
+			assert.Equal(t, tC.wantOK, ok)
+			assert.Equal(t, tC.want, actual)
+		})
+	}
+}
+
+func TestRepositoryReplaceMocks(t *testing.T) {
+	repo := NewRepository()
+	posted := Mock{Request: Request{Path: "/posted"}}
+	loaded := Mock{Request: Request{Path: "/loaded"}}
+	reloaded := Mock{Request: Request{Path: "/reloaded"}}
+	repo.SetMock(TestIDShared, posted)
+	repo.ReplaceMocks(TestIDShared, nil, Mocks{loaded})
+	assert.ElementsMatch(t, []Mock{posted, loaded}, slices.Collect(repo.Mocks(TestIDShared)))
+
+	repo.ReplaceMocks(TestIDShared, []string{loaded.ID()}, Mocks{reloaded})
+	assert.ElementsMatch(t, []Mock{posted, reloaded}, slices.Collect(repo.Mocks(TestIDShared)))
+}
@@ -0,0 +1,120 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TemplateData is passed to templates of a [Response] whose Template is true.
+//
+//	{"id": "{{uuid}}", "body": {{json .Body.body}}, "number": {{.PathVars.number}}}
+type TemplateData struct {
+	Method string
+	Path   string
+	// PathVars are variables captured by [PathMatcherTypePattern] or [PathMatcherTypeRegexp].
+	PathVars map[string]string
+	// Query and Header are used like {{.Query.Get "page"}}.
+	Query  url.Values
+	Header http.Header
+	// Body is the JSON-decoded body. It is nil if the body is not JSON.
+	Body any
+	// RawBody is the body as it is.
+	RawBody string
+}
+
+func NewTemplateData(r *http.Request, body []byte, pathVars map[string]string) *TemplateData {
+	data := TemplateData{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		PathVars: pathVars,
+		Query:    r.URL.Query(),
+		Header:   r.Header,
+		RawBody:  string(body),
+	}
+	if data.PathVars == nil {
+		data.PathVars = map[string]string{}
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err == nil {
+		data.Body = v
+	}
+	return &data
+}
+
+var templateFuncs = template.FuncMap{
+	"uuid": uuid.NewString,
+	"now":  time.Now,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+func renderTemplate(name string, text string, data *TemplateData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	b := strings.Builder{}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (r Response) validateTemplate() error {
+	if !r.Template {
+		return nil
+	}
+	if _, err := parseTemplate("body", r.Body); err != nil {
+		return fmt.Errorf("body: %w", err)
+	}
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			if _, err := parseTemplate(k, v); err != nil {
+				return fmt.Errorf("header %s: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Render returns the response whose body and header values are rendered with data.
+// It returns r as it is if Template is false.
+func (r Response) Render(data *TemplateData) (Response, error) {
+	if !r.Template {
+		return r, nil
+	}
+
+	rendered := r
+	body, err := renderTemplate("body", r.Body, data)
+	if err != nil {
+		return Response{}, fmt.Errorf("body: %w", err)
+	}
+	rendered.Body = body
+	rendered.Header = http.Header{}
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			s, err := renderTemplate(k, v, data)
+			if err != nil {
+				return Response{}, fmt.Errorf("header %s: %w", k, err)
+			}
+			rendered.Header.Add(k, s)
+		}
+	}
+	return rendered, nil
+}
@@ -0,0 +1,83 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRender(t *testing.T) {
+	r := httptest.NewRequest(
+		http.MethodPost,
+		"/repos/o/r/issues/3/comments?page=2",
+		strings.NewReader(`{"body":"hello \"world\""}`),
+	)
+	r.Header.Set("X-Foo", "foo")
+	data := NewTemplateData(r, []byte(`{"body":"hello \"world\""}`), map[string]string{"number": "3"})
+
+	testCases := []struct {
+		desc     string
+		input    Response
+		expected Response
+		wantErr  string
+	}{
+		{
+			desc:     "ok - not a template",
+			input:    Response{Status: 201, Body: `{{.RawBody}}`},
+			expected: Response{Status: 201, Body: `{{.RawBody}}`},
+		},
+		{
+			desc: "ok - request context",
+			input: Response{
+				Status:   201,
+				Header:   http.Header{"X-Foo": {"{{.Header.Get \"X-Foo\"}}-{{.Query.Get \"page\"}}"}},
+				Body:     `{"number":{{.PathVars.number}},"body":{{json .Body.body}},"method":"{{.Method}}","path":"{{.Path}}"}`,
+				Template: true,
+			},
+			expected: Response{
+				Status:   201,
+				Header:   http.Header{"X-Foo": {"foo-2"}},
+				Body:     `{"number":3,"body":"hello \"world\"","method":"POST","path":"/repos/o/r/issues/3/comments"}`,
+				Template: true,
+			},
+		},
+		{
+			desc:    "ng - execution error",
+			input:   Response{Body: `{{index .Query.page 5}}`, Template: true},
+			wantErr: "body: ",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, err := tC.input.Render(data)
+			if tC.wantErr != "" {
+				require.ErrorContains(t, err, tC.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, actual)
+		})
+	}
+}
+
+func TestResponseRenderHelpers(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	res, err := Response{Body: `{{uuid}} {{now.Year}}`, Template: true}.Render(NewTemplateData(r, nil, nil))
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f-]{36} \d{4}$`, res.Body)
+}
+
+func TestMockValidateTemplate(t *testing.T) {
+	err := Mock{Response: Response{Body: `{{.Body`, Template: true}}.Validate()
+	assert.ErrorContains(t, err, "response: body: ")
+
+	err = Mock{Responses: []Response{{Header: http.Header{"X": {"{{"}}, Template: true}}}.Validate()
+	assert.ErrorContains(t, err, "responses[0]: header X: ")
+
+	err = Mock{Response: Response{Body: `{{.Body`}}.Validate()
+	assert.NoError(t, err)
+}
@@ -6,9 +6,20 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/journal"
 	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/mock"
 )
 
+// testIDFromRequest returns the test ID of the namespace which an admin request operates on.
+// The path parameter testId takes precedence over the E2E-TestId header.
+// It returns [mock.TestIDShared] if neither is given.
+func testIDFromRequest(r *http.Request) string {
+	if testID := r.PathValue("testId"); testID != "" {
+		return testID
+	}
+	return r.Header.Get(mock.HeaderNameTestID)
+}
+
 func PostAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
@@ -25,7 +36,13 @@ func PostAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 			return
 		}
 
-		caseRepo.SetMock(c)
+		if err := c.Validate(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "invalid mock: %s", err) //nolint:errcheck
+			return
+		}
+
+		caseRepo.SetMock(testIDFromRequest(r), c)
 
 		w.WriteHeader(http.StatusNoContent)
 	}
@@ -33,7 +50,7 @@ func PostAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 
 func DeleteAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		caseRepo.Clear()
+		caseRepo.Clear(testIDFromRequest(r))
 
 		w.WriteHeader(http.StatusNoContent)
 	}
@@ -42,7 +59,7 @@ func DeleteAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 func GetAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ret := []mock.Mock{}
-		for m := range caseRepo.Mocks() {
+		for m := range caseRepo.Mocks(testIDFromRequest(r)) {
 			ret = append(ret, m)
 		}
 
@@ -57,3 +74,32 @@ func GetAdminCase(caseRepo *mock.Repository) http.HandlerFunc {
 		w.Write(body) // nolint:errcheck
 	}
 }
+
+func GetAdminRequests(journalRepo *journal.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := journal.NewFilterFromValues(r.URL.Query())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "failed to parse query") //nolint:errcheck
+			return
+		}
+
+		body, err := json.Marshal(journalRepo.Entries(testIDFromRequest(r), filter))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal requests") //nolint:errcheck
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(body) // nolint:errcheck
+	}
+}
+
+func DeleteAdminRequests(journalRepo *journal.Repository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		journalRepo.Clear(testIDFromRequest(r))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
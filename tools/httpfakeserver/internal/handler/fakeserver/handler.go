@@ -1,23 +1,67 @@
 package fakeserver
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/journal"
 	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/mock"
 )
 
-func HandleFunc(caseRepo *mock.Repository) http.HandlerFunc {
+func HandleFunc(caseRepo *mock.Repository, journalRepo *journal.Repository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		for m := range caseRepo.Mocks() {
-			if !m.Match(r) {
+		testID := r.Header.Get(mock.HeaderNameTestID)
+		entry := journal.Entry{
+			Method:     r.Method,
+			URL:        r.URL.String(),
+			Header:     r.Header.Clone(),
+			MockID:     journal.MockIDUnmatched,
+			ReceivedAt: time.Now(),
+			TestID:     testID,
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "failed to read body") //nolint:errcheck
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		entry.Body = string(body)
+
+		for namespace, m := range caseRepo.Candidates(testID) {
+			pathVars, matched := m.MatchWithPathVars(r)
+			if !matched {
+				continue
+			}
+			n, ok := caseRepo.Consume(namespace, m.ID())
+			if !ok {
+				// the mock has been used up to Times
 				continue
 			}
 
-			m.WriteResponse(w)
+			entry.MockID = m.ID()
+			entry.PathVars = pathVars
+			journalRepo.Add(entry)
+			res, ok := m.ResponseAt(n)
+			if !ok {
+				w.WriteHeader(http.StatusNotImplemented)
+				fmt.Fprintf(w, "responses of the matched case are exhausted") //nolint:errcheck
+				return
+			}
+			res, err = res.Render(mock.NewTemplateData(r, body, pathVars))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, "failed to render response: %s", err) //nolint:errcheck
+				return
+			}
+			res.Write(w, r)
 			return
 		}
 
+		journalRepo.Add(entry)
 		w.WriteHeader(http.StatusNotImplemented)
 		fmt.Fprintf(w, "no matched to cases") //nolint:errcheck
 	}
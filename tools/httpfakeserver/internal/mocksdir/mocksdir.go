@@ -0,0 +1,97 @@
+// Package mocksdir loads mocks files of a directory into the shared namespace.
+package mocksdir
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/mock"
+)
+
+// reloadDelay is how long Watch waits for following events before reloading,
+// because saving a file often causes several events.
+const reloadDelay = 100 * time.Millisecond
+
+type Loader struct {
+	dir  string
+	repo *mock.Repository
+	// loadedIDs are IDs of the mocks loaded last time.
+	// They are replaced on reload so that mocks posted via the admin API are kept.
+	loadedIDs []string
+}
+
+// Load loads mocks files of dir into the shared namespace of repo.
+// Nothing is loaded if any of the files is invalid.
+func Load(repo *mock.Repository, dir string) (*Loader, error) {
+	l := Loader{dir: dir, repo: repo}
+	if err := l.reload(); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (l *Loader) reload() error {
+	mocks, err := mock.LoadMocksDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(mocks))
+	for _, m := range mocks {
+		ids = append(ids, m.ID())
+	}
+	l.repo.ReplaceMocks(mock.TestIDShared, l.loadedIDs, mocks)
+	l.loadedIDs = ids
+	return nil
+}
+
+// Watch reloads the mocks files when they are changed until ctx is done.
+// If the files become invalid, the mocks loaded last time are kept.
+func (l *Loader) Watch(ctx context.Context, logger *slog.Logger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to fsnotify.NewWatcher: %w", err)
+	}
+	if err := watcher.Add(l.dir); err != nil {
+		watcher.Close() //nolint:errcheck
+		return fmt.Errorf("failed to watch %s: %w", l.dir, err)
+	}
+
+	go func() {
+		defer watcher.Close() //nolint:errcheck
+
+		timer := time.NewTimer(0)
+		<-timer.C
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if mock.IsMocksFile(filepath.Base(ev.Name)) {
+					timer.Reset(reloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error("failed to watch mocks dir", slog.Any("error", err))
+			case <-timer.C:
+				if err := l.reload(); err != nil {
+					logger.Error("failed to reload mocks dir", slog.String("dir", l.dir), slog.Any("error", err))
+					continue
+				}
+				logger.Info("reloaded mocks dir", slog.String("dir", l.dir), slog.Int("mocks", len(l.loadedIDs)))
+			}
+		}
+	}()
+
+	return nil
+}
@@ -3,22 +3,65 @@ package httpfakeserver
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/suzuito/sandbox2-common-go/libs/utils"
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/journal"
 	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/domain/mock"
 	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/handler/admin"
 	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/handler/fakeserver"
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver/internal/mocksdir"
 )
 
 type Request = mock.Request
 type Response = mock.Response
 type Mock = mock.Mock
 type Mocks = mock.Mocks
+type BodyMatcher = mock.BodyMatcher
+type BodyMatcherType = mock.BodyMatcherType
+type JSONPathPredicate = mock.JSONPathPredicate
+type PathMatcherType = mock.PathMatcherType
+type WhenExhausted = mock.WhenExhausted
+type TemplateData = mock.TemplateData
+type Fault = mock.Fault
+type RecordedRequest = journal.Entry
+type RecordedRequests = journal.Entries
+type RequestsFilter = journal.Filter
+
+const (
+	BodyMatcherTypeExact    = mock.BodyMatcherTypeExact
+	BodyMatcherTypeJSON     = mock.BodyMatcherTypeJSON
+	BodyMatcherTypeJSONPath = mock.BodyMatcherTypeJSONPath
+	BodyMatcherTypeRegexp   = mock.BodyMatcherTypeRegexp
+)
+
+const (
+	PathMatcherTypeExact   = mock.PathMatcherTypeExact
+	PathMatcherTypePattern = mock.PathMatcherTypePattern
+	PathMatcherTypeGlob    = mock.PathMatcherTypeGlob
+	PathMatcherTypeRegexp  = mock.PathMatcherTypeRegexp
+)
+
+const (
+	WhenExhaustedRepeatLast = mock.WhenExhaustedRepeatLast
+	WhenExhaustedFail       = mock.WhenExhaustedFail
+)
+
+const (
+	MockIDUnmatched  = journal.MockIDUnmatched
+	HeaderNameTestID = mock.HeaderNameTestID
+)
 
 type Options struct {
 	Port          int
 	BasePathAdmin string
+	// MocksDir is optional. Mocks files (*.json, *.yaml) in it are loaded into the shared namespace on startup.
+	// Main fails if any of them is invalid.
+	MocksDir string
+	// WatchMocksDir reloads the mocks files of MocksDir when they are changed.
+	WatchMocksDir bool
 }
 
 func Main(ctx context.Context, o Options) int {
@@ -27,12 +70,45 @@ func Main(ctx context.Context, o Options) int {
 		port = o.Port
 	}
 
-	basePathAdmin := "/admin"
-	if o.BasePathAdmin != "" {
-		basePathAdmin = o.BasePathAdmin
+	handler, err := newHandler(ctx, o)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
 	}
 
+	exitCode := utils.RunHandlerWithGracefulShutdown(
+		ctx,
+		handler,
+		port,
+		utils.Options{
+			WaitSecondsUntilGracefulShutdownIsStarted:   1,
+			GracefulShutdownTimeoutSeconds:              1,
+			ForcefullyRequestCancellationTimeoutSeconds: 1,
+		},
+	)
+
+	return exitCode.Int()
+}
+
+// newHandler returns the handler of admin endpoints and the fake server.
+// Mocks dir is watched until ctx is done.
+func newHandler(ctx context.Context, o Options) (http.Handler, error) {
+	basePathAdmin := basePathAdminOrDefault(o.BasePathAdmin)
+
 	caseRepository := mock.NewRepository()
+	journalRepository := journal.NewRepository()
+
+	if o.MocksDir != "" {
+		loader, err := mocksdir.Load(caseRepository, o.MocksDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mocks dir: %s:\n%w", o.MocksDir, err)
+		}
+		if o.WatchMocksDir {
+			if err := loader.Watch(ctx, slog.Default()); err != nil {
+				return nil, fmt.Errorf("failed to watch mocks dir: %w", err)
+			}
+		}
+	}
 
 	mux := http.NewServeMux()
 
@@ -42,35 +118,42 @@ func Main(ctx context.Context, o Options) int {
 			w.WriteHeader(http.StatusOK)
 		},
 	)
-	mux.HandleFunc(
-		fmt.Sprintf("POST %s/cases", basePathAdmin),
-		admin.PostAdminCase(caseRepository),
-	)
-	mux.HandleFunc(
-		fmt.Sprintf("DELETE %s/cases", basePathAdmin),
-		admin.DeleteAdminCase(caseRepository),
-	)
-	mux.HandleFunc(
-		fmt.Sprintf("GET %s/cases", basePathAdmin),
-		admin.GetAdminCase(caseRepository),
-	)
+	// Each admin endpoint is also served under tests/{testId}/ to operate on the namespace of the test ID.
+	for _, basePath := range []string{basePathAdmin, basePathAdmin + "/tests/{testId}"} {
+		mux.HandleFunc(
+			fmt.Sprintf("POST %s/cases", basePath),
+			admin.PostAdminCase(caseRepository),
+		)
+		mux.HandleFunc(
+			fmt.Sprintf("DELETE %s/cases", basePath),
+			admin.DeleteAdminCase(caseRepository),
+		)
+		mux.HandleFunc(
+			fmt.Sprintf("GET %s/cases", basePath),
+			admin.GetAdminCase(caseRepository),
+		)
+		mux.HandleFunc(
+			fmt.Sprintf("GET %s/requests", basePath),
+			admin.GetAdminRequests(journalRepository),
+		)
+		mux.HandleFunc(
+			fmt.Sprintf("DELETE %s/requests", basePath),
+			admin.DeleteAdminRequests(journalRepository),
+		)
+	}
 	mux.HandleFunc(
 		"/",
-		fakeserver.HandleFunc(caseRepository),
+		fakeserver.HandleFunc(caseRepository, journalRepository),
 	)
 
-	exitCode := utils.RunHandlerWithGracefulShutdown(
-		ctx,
-		mux,
-		port,
-		utils.Options{
-			WaitSecondsUntilGracefulShutdownIsStarted:   1,
-			GracefulShutdownTimeoutSeconds:              1,
-			ForcefullyRequestCancellationTimeoutSeconds: 1,
-		},
-	)
+	return mux, nil
+}
 
-	return exitCode.Int()
+func basePathAdminOrDefault(basePathAdmin string) string {
+	if basePathAdmin == "" {
+		return "/admin"
+	}
+	return basePathAdmin
 }
 
 type MainAsyncReturnValue struct {
@@ -78,6 +161,8 @@ type MainAsyncReturnValue struct {
 	Done         func()
 }
 
+// MainAsync runs [Main] in a goroutine. In tests, [NewServer] is handier
+// because it uses an ephemeral port and returns after the server starts listening.
 func MainAsync(ctx context.Context, o Options) MainAsyncReturnValue {
 	chServerDone := make(chan int)
 
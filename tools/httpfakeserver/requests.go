@@ -0,0 +1,80 @@
+package httpfakeserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// BaseURLAdminForTest returns the base URL of admin endpoints which operate on the namespace of testID.
+// Mocks and recorded requests of the namespace are used only by requests with E2E-TestId header of testID.
+func BaseURLAdminForTest(baseURLAdmin string, testID string) string {
+	return baseURLAdmin + "/tests/" + url.PathEscape(testID)
+}
+
+// GetRequests requests GET <admin>/requests and returns recorded requests matching filter in the order of receipt.
+// baseURLAdmin is like http://localhost:8080/admin.
+func GetRequests(
+	ctx context.Context,
+	cli *http.Client,
+	baseURLAdmin string,
+	filter RequestsFilter,
+) (RecordedRequests, error) {
+	reqURL := baseURLAdmin + "/requests"
+	if values := filter.Values(); len(values) > 0 {
+		reqURL += "?" + values.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to http.NewRequest: %w", err)
+	}
+
+	body, err := doAdminRequest(cli, req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := RecordedRequests{}
+	if err := json.Unmarshal(body, &ret); err != nil {
+		return nil, fmt.Errorf("failed to json.Unmarshal: %w", err)
+	}
+	return ret, nil
+}
+
+// DeleteRequests requests DELETE <admin>/requests to clear recorded requests.
+func DeleteRequests(
+	ctx context.Context,
+	cli *http.Client,
+	baseURLAdmin string,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, baseURLAdmin+"/requests", nil)
+	if err != nil {
+		return fmt.Errorf("failed to http.NewRequest: %w", err)
+	}
+
+	_, err = doAdminRequest(cli, req, http.StatusNoContent)
+	return err
+}
+
+func doAdminRequest(cli *http.Client, req *http.Request, wantStatus int) ([]byte, error) {
+	res, err := cli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to http request: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if res.StatusCode != wantStatus {
+		return nil, &APIError{StatusCode: res.StatusCode, Message: string(body)}
+	}
+
+	return body, nil
+}
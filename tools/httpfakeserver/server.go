@@ -0,0 +1,105 @@
+package httpfakeserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// Server is httpfakeserver running in the process on an ephemeral port. See [NewServer].
+type Server struct {
+	// URL is the base URL of the server like http://127.0.0.1:54321.
+	URL *url.URL
+	// Client operates on the admin API of the server.
+	Client *Client
+
+	server *http.Server
+	cancel context.CancelFunc
+	// serveDone is closed when Serve of server returns and serveErr is set before that.
+	serveDone chan struct{}
+	serveErr  error
+}
+
+// NewServer starts httpfakeserver in the process and returns it after it starts listening.
+// Port of o is ignored and an ephemeral port of the loopback address is used,
+// so that tests can run servers in parallel. Call [Server.Close] to stop it.
+func NewServer(o Options) (*Server, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler, err := newHandler(ctx, o)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to net.Listen: %w", err)
+	}
+
+	baseURL := &url.URL{Scheme: "http", Host: listener.Addr().String()}
+	s := &Server{
+		URL:       baseURL,
+		Client:    NewClient(baseURL, basePathAdminOrDefault(o.BasePathAdmin), &http.Client{}),
+		server:    &http.Server{Handler: handler},
+		cancel:    cancel,
+		serveDone: make(chan struct{}),
+	}
+	go s.serve(listener)
+	return s, nil
+}
+
+func (s *Server) serve(listener net.Listener) {
+	defer close(s.serveDone)
+	if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.serveErr = fmt.Errorf("failed to serve: %w", err)
+	}
+}
+
+// Close stops the server immediately.
+// It also returns the error which stopped the server before Close if any.
+func (s *Server) Close() error {
+	s.cancel()
+	err := s.server.Close()
+	<-s.serveDone
+	return errors.Join(err, s.serveErr)
+}
+
+// Transport returns an [http.RoundTripper] which sends every request to the server
+// keeping the path and the query, e.g. https://api.github.com/repos/o/r to <URL>/repos/o/r.
+// Set it to an http.Client of the code under test to fake the upstream.
+func (s *Server) Transport() http.RoundTripper {
+	return s.TransportForTest("")
+}
+
+// TransportForTest is like [Server.Transport] but also sets E2E-TestId header of testID
+// so that requests are matched with mocks in the namespace of testID.
+// See [Client.ForTest].
+func (s *Server) TransportForTest(testID string) http.RoundTripper {
+	return &roundTripper{
+		origin: http.DefaultTransport,
+		target: s.URL,
+		testID: testID,
+	}
+}
+
+type roundTripper struct {
+	origin http.RoundTripper
+	target *url.URL
+	testID string
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip must not modify the request.
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = ""
+	if t.testID != "" {
+		req.Header.Set(HeaderNameTestID, t.testID)
+	}
+	return t.origin.RoundTrip(req)
+}
@@ -0,0 +1,27 @@
+package httpfakeserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_CloseReturnsServeError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	_, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		server:    &http.Server{Handler: http.NotFoundHandler()},
+		cancel:    cancel,
+		serveDone: make(chan struct{}),
+	}
+	s.serve(listener)
+
+	assert.ErrorContains(t, s.Close(), "failed to serve:")
+}
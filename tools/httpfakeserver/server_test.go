@@ -0,0 +1,71 @@
+package httpfakeserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/httpfakeserver"
+)
+
+func TestNewServer(t *testing.T) {
+	ctx := context.Background()
+	server, err := httpfakeserver.NewServer(httpfakeserver.Options{})
+	require.NoError(t, err)
+	defer server.Close() //nolint:errcheck
+
+	require.NoError(t, server.Client.AddMock(ctx, httpfakeserver.Mock{
+		Request:  httpfakeserver.Request{Method: http.MethodGet, Path: "/repos/o/r"},
+		Response: httpfakeserver.Response{Status: http.StatusOK, Body: "shared"},
+	}))
+	require.NoError(t, server.Client.ForTest("t1").AddMock(ctx, httpfakeserver.Mock{
+		Request:  httpfakeserver.Request{Method: http.MethodGet, Path: "/repos/o/r"},
+		Response: httpfakeserver.Response{Status: http.StatusOK, Body: "t1"},
+	}))
+
+	get := func(t *testing.T, transport http.RoundTripper) string {
+		t.Helper()
+		cli := http.Client{Transport: transport}
+		res, err := cli.Get("https://api.github.com/repos/o/r")
+		require.NoError(t, err)
+		defer res.Body.Close() //nolint:errcheck
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+	assert.Equal(t, "shared", get(t, server.Transport()))
+	assert.Equal(t, "t1", get(t, server.TransportForTest("t1")))
+
+	recorded, err := server.Client.ForTest("t1").WaitForRequest(ctx, httpfakeserver.RequestsFilter{Path: "/repos/o/r"})
+	require.NoError(t, err)
+	assert.Equal(t, "t1", recorded.TestID)
+
+	t.Run("servers listen on different ports", func(t *testing.T) {
+		another, err := httpfakeserver.NewServer(httpfakeserver.Options{BasePathAdmin: "/_admin"})
+		require.NoError(t, err)
+		defer another.Close() //nolint:errcheck
+
+		assert.NotEqual(t, server.URL.Host, another.URL.Host)
+		mocks, err := another.Client.ListMocks(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, mocks)
+	})
+
+	t.Run("closed server refuses requests", func(t *testing.T) {
+		another, err := httpfakeserver.NewServer(httpfakeserver.Options{})
+		require.NoError(t, err)
+		require.NoError(t, another.Close())
+
+		_, err = another.Client.ListMocks(ctx)
+		assert.Error(t, err)
+		assert.NoError(t, another.Close())
+	})
+
+	t.Run("invalid mocks dir", func(t *testing.T) {
+		_, err := httpfakeserver.NewServer(httpfakeserver.Options{MocksDir: "/not/found"})
+		assert.ErrorContains(t, err, "failed to load mocks dir: /not/found:")
+	})
+}
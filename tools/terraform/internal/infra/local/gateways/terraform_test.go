@@ -0,0 +1,97 @@
+package gateways
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suzuito/sandbox2-common-go/tools/fakecmd/domains"
+	"github.com/suzuito/sandbox2-common-go/tools/terraform/internal/domains/terraformmodels/module"
+)
+
+func TestMain(m *testing.M) {
+	domains.RunIfInProcess()
+	os.Exit(m.Run())
+}
+
+func TestTerraformGateway(t *testing.T) {
+	t.Parallel()
+
+	faker := domains.MustInProcess()
+	t.Cleanup(func() {
+		faker.Cleanup() //nolint:errcheck
+	})
+
+	behavior := func(exitCode uint8, args ...string) domains.Behavior {
+		return domains.Behavior{
+			Type:        domains.BehaviorTypeStdoutStderrExitCode,
+			ArgsMatcher: &domains.ArgsMatcher{Type: domains.ArgsMatcherTypeExact, Args: args},
+			BehaviorStdoutStderrExitCode: &domains.BehaviorStdoutStderrExitCode{
+				Stdout:   "this is stdout",
+				Stderr:   "this is stderr",
+				ExitCode: exitCode,
+			},
+		}
+	}
+
+	m := module.Module{AbsPath: module.ModulePath("/roots/r1")}
+	ctx := context.Background()
+
+	t.Run("Init", func(t *testing.T) {
+		t.Parallel()
+
+		fcmd := faker.AddInTest(t, domains.Behaviors{
+			behavior(0, "-chdir=/roots/r1", "init", "-no-color"),
+			behavior(1, "-chdir=/roots/r1", "init", "-no-color"),
+		})
+		g := NewTerraformGateway(fcmd.DirPath().FilePathCommand(), io.Discard, io.Discard)
+
+		require.NoError(t, g.Init(ctx, &m))
+		require.EqualError(t, g.Init(ctx, &m), "failed to init")
+		fcmd.AssertAllBehaviorsConsumed(t)
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		t.Parallel()
+
+		fcmd := faker.AddInTest(t, domains.Behaviors{
+			behavior(0, "-chdir=/roots/r1", "plan", "-no-color", "-detailed-exitcode"),
+			behavior(2, "-chdir=/roots/r1", "plan", "-no-color", "-detailed-exitcode"),
+			behavior(1, "-chdir=/roots/r1", "plan", "-no-color", "-detailed-exitcode"),
+		})
+		g := NewTerraformGateway(fcmd.DirPath().FilePathCommand(), io.Discard, io.Discard)
+
+		result, err := g.Plan(ctx, &m)
+		require.NoError(t, err)
+		assert.False(t, result.IsPlanDiff)
+		assert.Contains(t, result.Stdout, "==== OUT ====\nthis is stdout==== END ====\nexit with 0\n")
+		assert.Equal(t, "this is stderr", result.Stderr)
+
+		result, err = g.Plan(ctx, &m)
+		require.NoError(t, err)
+		assert.True(t, result.IsPlanDiff)
+		assert.Contains(t, result.Stdout, "==== OUT ====\nthis is stdout==== END ====\nexit with 2\n")
+
+		_, err = g.Plan(ctx, &m)
+		require.EqualError(t, err, "failed to plan")
+		fcmd.AssertAllBehaviorsConsumed(t)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		t.Parallel()
+
+		fcmd := faker.AddInTest(t, domains.Behaviors{
+			behavior(0, "-chdir=/roots/r1", "apply", "-no-color", "-auto-approve"),
+		})
+		g := NewTerraformGateway(fcmd.DirPath().FilePathCommand(), io.Discard, io.Discard)
+
+		result, err := g.Apply(ctx, &m)
+		require.NoError(t, err)
+		assert.Contains(t, result.Stdout, "==== OUT ====\nthis is stdout==== END ====\nexit with 0\n")
+		assert.Equal(t, "this is stderr", result.Stderr)
+		fcmd.AssertAllBehaviorsConsumed(t)
+	})
+}